@@ -8,10 +8,28 @@ import (
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
 )
 
+// AnalyzerConfig configures the analyzer
+type AnalyzerConfig struct {
+	// CoverageURLs are pinned to commit hashes.
+	// Coverers are compared by CoverageLabels, so footnotes are not rewritten on every commit
+	Permalinks bool
+	// Ids of new footnotes, FootnoteIdsNumeric if empty. Existing ids are kept, ref. NewFormatterEx() for migration
+	FootnoteIds FootnoteIdStrategy
+	// Overrides default severities of semantic errors
+	Severities Severities
+	// Semantic errors of the baseline are suppressed, nil if there is no baseline
+	Baseline *Baseline
+}
+
 type analyzer struct {
-	coverages map[RequirementId]*requirementCoverage // RequirementId -> RequirementCoverage
+	permalinks  bool
+	footnoteIds FootnoteIdStrategy
+	severities  Severities
+	baseline    *Baseline
+	coverages   map[RequirementId]*requirementCoverage // RequirementId -> RequirementCoverage
 
 	// RequirementIds sorted by position in the file
 	// Position is coverages[RequirementId]Site.FilePath + coverages[RequirementId]Site.Line
@@ -29,7 +47,15 @@ type requirementCoverage struct {
 }
 
 func NewAnalyzer() IAnalyzer {
+	return NewAnalyzerEx(&AnalyzerConfig{})
+}
+
+func NewAnalyzerEx(acfg *AnalyzerConfig) IAnalyzer {
 	return &analyzer{
+		permalinks:        acfg.Permalinks,
+		footnoteIds:       acfg.FootnoteIds,
+		severities:        acfg.Severities,
+		baseline:          acfg.Baseline,
 		coverages:         make(map[RequirementId]*requirementCoverage),
 		changedFootnotes:  make(map[RequirementId]bool),
 		maxFootnoteIntIds: make(map[FilePath]int),
@@ -50,6 +76,22 @@ func (a *analyzer) Analyze(files []FileStructure) (*AnalyzerResult, error) {
 
 	a.analyzeMdActions(result)
 
+	a.analyzeOrphanedFootnotes(files, result)
+
+	a.analyzeSummaryActions(files, result)
+
+	suppressions := make(map[string][]Suppression)
+	for _, file := range files {
+		if len(file.Suppressions) > 0 {
+			suppressions[file.Path] = file.Suppressions
+		}
+	}
+	result.ProcessingErrors, result.SuppressedErrors = suppressErrors(suppressions, result.ProcessingErrors)
+	var baselined []ProcessingError
+	result.ProcessingErrors, baselined = a.baseline.split(result.ProcessingErrors)
+	result.SuppressedErrors = append(result.SuppressedErrors, baselined...)
+	a.severities.apply(result.ProcessingErrors)
+
 	return result, nil
 }
 
@@ -91,13 +133,15 @@ func (a *analyzer) analyzeMdActions(result *AnalyzerResult) {
 
 		var footnoteId CoverageFootnoteId
 		if !coverage.Site.HasAnnotationRef {
-			footnoteId = a.nextFootnoteId(coverage.FileStructure.Path)
+			footnoteId = a.nextFootnoteId(coverage.FileStructure.Path, coverage.Site.RequirementName)
 		} else {
 			footnoteId = coverage.Site.CoverageFootnoteId
 		}
 
 		// Check if site action is needed
-		if !coverage.Site.HasAnnotationRef || coverage.Site.CoverageStatusWord != coverageStatus {
+		// Mismatched CoverageStatusEmoji is repaired as well
+		if !coverage.Site.HasAnnotationRef || coverage.Site.CoverageStatusWord != coverageStatus ||
+			coverage.Site.CoverageStatusEmoji != CoverageStatusEmojiOf(coverageStatus) {
 			siteAction := MdAction{
 				Type:            ActionSite,
 				Path:            coverage.FileStructure.Path,
@@ -113,7 +157,7 @@ func (a *analyzer) analyzeMdActions(result *AnalyzerResult) {
 		}
 
 		// Footnote action is needed if coverers are different or site is not annotated
-		if !areCoverersEqualByURLs(coverage.CurrentCoverers, coverage.NewCoverers) || coverage.CurrentCoverers == nil {
+		if !a.areCoverersEqual(coverage.CurrentCoverers, coverage.NewCoverers) || coverage.CurrentCoverers == nil {
 
 			a.changedFootnotes[requirementId] = true
 
@@ -152,6 +196,101 @@ func (a *analyzer) analyzeMdActions(result *AnalyzerResult) {
 	}
 }
 
+// analyzeOrphanedFootnotes generates ActionFootnoteRemove for every CoverageFootnote that is not referenced by any RequirementSite of the file,
+// e.g. the site is deleted or renamed. Footnotes without the CoverageFootnoteHint are not generated by reqmd and are kept,
+// footnotes that are referenced by sites on ignored lines are kept as well
+func (a *analyzer) analyzeOrphanedFootnotes(files []FileStructure, result *AnalyzerResult) {
+	for _, file := range files {
+		if file.Type != FileTypeMarkdown || file.PackageId == "" {
+			continue
+		}
+		referenced := make(map[CoverageFootnoteId]bool)
+		for _, site := range file.Requirements {
+			if site.HasAnnotationRef {
+				referenced[site.CoverageFootnoteId] = true
+			}
+		}
+		for _, cf := range file.CoverageFootnotes {
+			if cf.PackageId == "" || referenced[cf.CoverageFootnoteId] || file.IgnoredFootnoteRefs[cf.CoverageFootnoteId] {
+				continue
+			}
+			result.MdActions[file.Path] = append(result.MdActions[file.Path], MdAction{
+				Type:            ActionFootnoteRemove,
+				Path:            file.Path,
+				Line:            cf.Line,
+				RequirementName: cf.RequirementName,
+			})
+		}
+	}
+}
+
+// analyzeSummaryActions generates ActionSummary for every SummaryBlock whose content is outdated
+func (a *analyzer) analyzeSummaryActions(files []FileStructure, result *AnalyzerResult) {
+	for _, file := range files {
+		if file.Type != FileTypeMarkdown {
+			continue
+		}
+		for _, block := range file.SummaryBlocks {
+			lines := FormatSummaryBlock(block.Scope, a.summaryRows(&file, block.Scope))
+			content := lines[1 : len(lines)-1]
+			if block.EndLine > block.Line && slices.Equal(block.Content, content) {
+				continue
+			}
+			result.MdActions[file.Path] = append(result.MdActions[file.Path], MdAction{
+				Type:    ActionSummary,
+				Path:    file.Path,
+				Line:    block.Line,
+				EndLine: block.EndLine,
+				Data:    strings.Join(lines, "\n"),
+			})
+		}
+	}
+}
+
+// summaryRows counts covered and uncovered requirements by type within the given scope
+func (a *analyzer) summaryRows(file *FileStructure, scope SummaryScope) []SummaryRow {
+	rowsByType := make(map[string]*SummaryRow)
+	for reqId, coverage := range a.coverages {
+		switch scope {
+		case SummaryScopePackage:
+			if reqId.PackageId != file.PackageId {
+				continue
+			}
+		default:
+			if coverage.FileStructure.Path != file.Path {
+				continue
+			}
+		}
+		reqType := ""
+		if strings.Contains(string(reqId.RequirementName), ".") {
+			reqType = ExtractTypeFromRequirement(string(reqId.RequirementName))
+		}
+		row, ok := rowsByType[reqType]
+		if !ok {
+			row = &SummaryRow{Type: reqType}
+			rowsByType[reqType] = row
+		}
+		if len(coverage.NewCoverers) > 0 {
+			row.Covered++
+		} else {
+			row.Uncovered++
+		}
+	}
+
+	rows := make([]SummaryRow, 0, len(rowsByType))
+	for _, row := range rowsByType {
+		rows = append(rows, *row)
+	}
+	// Untyped requirements go last
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Type == "" || rows[j].Type == "" {
+			return rows[j].Type == "" && rows[i].Type != ""
+		}
+		return rows[i].Type < rows[j].Type
+	})
+	return rows
+}
+
 func (a *analyzer) buildRequirementCoverages(files []FileStructure, errors *[]ProcessingError) error {
 
 	// Processes files to analyze and manage requirements and their coverage.
@@ -253,9 +392,12 @@ func (a *analyzer) buildRequirementCoverages(files []FileStructure, errors *[]Pr
 			if coverage, exists := a.coverages[tag.RequirementId]; exists {
 				coverer := &Coverer{
 					CoverageLabel: file.RelativePath + ":" + fmt.Sprint(tag.Line) + ":" + tag.CoverageType,
-					CoverageURL:   file.FileURL() + "#L" + strconv.Itoa(tag.Line),
+					CoverageURL:   file.CoverageURL(tag.Line),
 					fileHash:      file.FileHash,
 				}
+				if !file.HasBaseURL() {
+					coverer.CoverageURL = file.RelativeCoverageURL(coverage.FileStructure.Path, tag.Line)
+				}
 				coverage.NewCoverers = append(coverage.NewCoverers, coverer)
 			}
 		}
@@ -266,7 +408,10 @@ func (a *analyzer) buildRequirementCoverages(files []FileStructure, errors *[]Pr
 
 // Finds the next available footnote Id for a given file
 // nolint
-func (a *analyzer) nextFootnoteId(filePath FilePath) CoverageFootnoteId {
+func (a *analyzer) nextFootnoteId(filePath FilePath, name RequirementName) CoverageFootnoteId {
+	if a.footnoteIds == FootnoteIdsName {
+		return NamedFootnoteId(name)
+	}
 	currentMax, ok := a.maxFootnoteIntIds[filePath]
 	if !ok {
 		currentMax = 0
@@ -295,3 +440,29 @@ func areCoverersEqualByURLs(a []*Coverer, b []*Coverer) bool {
 	}
 	return 0 == slices.CompareFunc(a, b, comparator)
 }
+
+// areCoverersEqual compares coverers by CoverageURLs.
+// In permalinks mode coverers are compared by CoverageLabels and current CoverageURLs shall be pinned to commit hashes.
+func (a *analyzer) areCoverersEqual(current []*Coverer, new []*Coverer) bool {
+	if !a.permalinks {
+		return areCoverersEqualByURLs(current, new)
+	}
+	for _, c := range current {
+		if !commitHashRegex.MatchString(c.CoverageURL) {
+			return false
+		}
+	}
+	return areCoverersEqualByLabels(current, new)
+}
+
+func areCoverersEqualByLabels(a []*Coverer, b []*Coverer) bool {
+	labels := func(coverers []*Coverer) []string {
+		res := make([]string, len(coverers))
+		for i, c := range coverers {
+			res[i] = c.CoverageLabel
+		}
+		slices.Sort(res)
+		return res
+	}
+	return slices.Equal(labels(a), labels(b))
+}
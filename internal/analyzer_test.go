@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,58 @@ func TestAnalyzer_error_Duplicates(t *testing.T) {
 	}
 }
 
+func TestAnalyzer_error_Suppressed(t *testing.T) {
+	files := []FileStructure{
+		{
+			// dupreqid is reported at the first site, so it is suppressed here
+			Path:         "file1.md",
+			Type:         FileTypeMarkdown,
+			PackageId:    "pkg",
+			Requirements: []RequirementSite{{RequirementName: "REQ001", Line: 10}},
+			Suppressions: []Suppression{{Line: 9, Codes: []string{"dupreqid"}}},
+		},
+		{
+			Path:         "file2.md",
+			Type:         FileTypeMarkdown,
+			PackageId:    "pkg",
+			Requirements: []RequirementSite{{RequirementName: "REQ001", Line: 10}},
+		},
+		{
+			Path:         "file3.md",
+			Type:         FileTypeMarkdown,
+			PackageId:    "pkg",
+			Requirements: []RequirementSite{{RequirementName: "REQ002", Line: 10}},
+		},
+		{
+			// Suppression at the second site does not suppress dupreqid of the first one
+			Path:         "file4.md",
+			Type:         FileTypeMarkdown,
+			PackageId:    "pkg",
+			Requirements: []RequirementSite{{RequirementName: "REQ002", Line: 10}},
+			Suppressions: []Suppression{{Line: 9, Codes: []string{"dupreqid"}}},
+		},
+		{
+			Path:         "file5.md",
+			Type:         FileTypeMarkdown,
+			Requirements: []RequirementSite{{RequirementName: "REQ003", Line: 10}},
+			Suppressions: []Suppression{{Line: 9, Codes: []string{"nopkgidreqs"}}},
+		},
+	}
+
+	result, err := NewAnalyzer().Analyze(files)
+	require.NoError(t, err)
+	require.Len(t, result.ProcessingErrors, 1)
+	require.Equal(t, "file3.md", result.ProcessingErrors[0].FilePath)
+	require.Equal(t, "dupreqid", result.ProcessingErrors[0].Code)
+
+	require.Len(t, result.SuppressedErrors, 2)
+	suppressed := map[string]string{}
+	for _, e := range result.SuppressedErrors {
+		suppressed[e.FilePath] = e.Code
+	}
+	require.Equal(t, map[string]string{"file1.md": "dupreqid", "file5.md": "nopkgidreqs"}, suppressed)
+}
+
 func TestAnalyzer_error_MissingPackageId(t *testing.T) {
 	analyzer := NewAnalyzer()
 
@@ -110,6 +163,23 @@ func TestAnalyzer_ActionFootnote_Bare(t *testing.T) {
 	assert.Equal(t, "[^1]: `[~pkg1/REQ001~impl]`", actions[1].Data)
 }
 
+// Bare requirement, ids are derived from RequirementNames
+func TestAnalyzer_ActionFootnote_Bare_Named(t *testing.T) {
+	analyzer := NewAnalyzerEx(&AnalyzerConfig{FootnoteIds: FootnoteIdsName})
+
+	mdFile := createMdStructureA("req.md", "pkg1", 10, "REQ001", CoverageStatusWordUncvrd)
+	mdFile.Requirements[0].HasAnnotationRef = false
+
+	result, err := analyzer.Analyze([]FileStructure{mdFile})
+	require.NoError(t, err)
+	require.Empty(t, result.ProcessingErrors)
+
+	actions := result.MdActions[mdFile.Path]
+	require.Len(t, actions, 2)
+	assert.Equal(t, FormatRequirementSite("REQ001", CoverageStatusWordUncvrd, "~REQ001~"), actions[0].Data)
+	assert.Equal(t, "[^~REQ001~]: `[~pkg1/REQ001~impl]`", actions[1].Data)
+}
+
 // Bare requirement, there is a footnote with CoverageFootnoteId == "19"
 func TestAnalyzer_ActionFootnote_Bare_f19(t *testing.T) {
 	analyzer := NewAnalyzer()
@@ -130,9 +200,9 @@ func TestAnalyzer_ActionFootnote_Bare_f19(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result.ProcessingErrors)
 
-	// Should generate both a footnote and status update action
+	// Should generate both a footnote and status update action, footnote 19 is orphaned
 	actions := result.MdActions[mdFile.Path]
-	require.Len(t, actions, 2)
+	require.Len(t, actions, 3)
 
 	// Verify status update action
 	assert.Equal(t, ActionSite, actions[0].Type)
@@ -144,6 +214,10 @@ func TestAnalyzer_ActionFootnote_Bare_f19(t *testing.T) {
 	assert.Equal(t, ActionFootnote, actions[1].Type)
 	assert.Equal(t, RequirementName("REQ001"), actions[1].RequirementName)
 	assert.Equal(t, "[^20]: `[~pkg1/REQ001~impl]`", actions[1].Data)
+
+	// Verify footnote removal action
+	assert.Equal(t, ActionFootnoteRemove, actions[2].Type)
+	assert.Equal(t, 20, actions[2].Line)
 }
 
 // Bare requirement, there is a footnote with CoverageFootnoteId == "19"
@@ -179,9 +253,9 @@ func TestAnalyzer_ActionFootnote_Bare_f19_r21(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result.ProcessingErrors)
 
-	// Should generate both a footnote and status update action
+	// Should generate both a footnote and status update action, footnote 19 is orphaned
 	actions := result.MdActions[mdFile.Path]
-	require.Len(t, actions, 3)
+	require.Len(t, actions, 4)
 
 	for _, action := range actions {
 		if action.Type == ActionSite {
@@ -202,6 +276,9 @@ func TestAnalyzer_ActionFootnote_Bare_f19_r21(t *testing.T) {
 			assert.Equal(t, RequirementName("REQ002"), action.RequirementName)
 			assert.Equal(t, "[^21]: `[~pkg1/REQ002~impl]`", action.Data)
 		}
+		if action.Type == ActionFootnoteRemove {
+			assert.Equal(t, 20, action.Line)
+		}
 	}
 
 }
@@ -357,10 +434,7 @@ func createMdStructureA(path string, pkgId PackageId, line int, reqName_ string,
 	var reqName RequirementName = RequirementName(reqName_)
 	var footnoteId CoverageFootnoteId = CoverageFootnoteId(reqName_)
 
-	emoji := CoverageStatusEmojiUncvrd
-	if cw == CoverageStatusWordCovered {
-		emoji = CoverageStatusEmojiCovered
-	}
+	emoji := CoverageStatusEmojiOf(cw)
 
 	fs := FileStructure{
 		Path:      path,
@@ -417,3 +491,127 @@ func createSourceFileStructure(path string, repoRootURL string, tags []CoverageT
 		RelativePath:      path, // For simplicity in tests
 	}
 }
+
+func TestAnalyzer_ActionSummary(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	mdFile := createMdStructureA("req.md", "pkg1", 10, "cmp.REQ001", CoverageStatusWordUncvrd)
+	mdFile.Requirements = append(mdFile.Requirements,
+		RequirementSite{RequirementName: "cmp.REQ002", Line: 11},
+		RequirementSite{RequirementName: "it.REQ003", Line: 12},
+		RequirementSite{RequirementName: "REQ004", Line: 13},
+	)
+	mdFile.SummaryBlocks = []SummaryBlock{{Line: 5, EndLine: 5, Scope: SummaryScopeFile}}
+
+	// Same package, another file
+	otherFile := createMdStructureA("other.md", "pkg1", 10, "it.REQ005", CoverageStatusWordUncvrd)
+	otherFile.SummaryBlocks = []SummaryBlock{{Line: 3, EndLine: 3, Scope: SummaryScopePackage}}
+
+	srcFile := createSourceFileStructure(
+		"src/impl.go",
+		"https://github.com/org/repo/blob/main",
+		[]CoverageTag{
+			createCoverageTag(StrToReqId("pkg1/cmp.REQ001"), "impl", 20),
+		},
+	)
+
+	result, err := analyzer.Analyze([]FileStructure{mdFile, otherFile, srcFile})
+	require.NoError(t, err)
+	require.Empty(t, result.ProcessingErrors)
+
+	findSummaryAction := func(path FilePath) *MdAction {
+		for _, action := range result.MdActions[path] {
+			if action.Type == ActionSummary {
+				return &action
+			}
+		}
+		return nil
+	}
+
+	{
+		action := findSummaryAction(mdFile.Path)
+		require.NotNil(t, action)
+		assert.Equal(t, 5, action.Line)
+		assert.Equal(t, 5, action.EndLine)
+		assert.Equal(t, strings.Join([]string{
+			"<!-- reqmd:summary -->",
+			"| Type | Covered | Uncovered | Total |",
+			"| ---- | ------- | --------- | ----- |",
+			"| cmp | 1 | 1 | 2 |",
+			"| it | 0 | 1 | 1 |",
+			"| - | 0 | 1 | 1 |",
+			"| **Total** | 1 | 3 | 4 |",
+			"<!-- /reqmd:summary -->",
+		}, "\n"), action.Data)
+	}
+
+	{
+		action := findSummaryAction(otherFile.Path)
+		require.NotNil(t, action)
+		assert.Contains(t, action.Data, "<!-- reqmd:summary package -->")
+		assert.Contains(t, action.Data, "| it | 0 | 2 | 2 |")
+		assert.Contains(t, action.Data, "| **Total** | 1 | 4 | 5 |")
+	}
+}
+
+func TestAnalyzer_ActionSummary_UpToDate(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	mdFile := createMdStructureA("req.md", "pkg1", 10, "cmp.REQ001", CoverageStatusWordUncvrd)
+	lines := FormatSummaryBlock(SummaryScopeFile, []SummaryRow{{Type: "cmp", Uncovered: 1}})
+	mdFile.SummaryBlocks = []SummaryBlock{{Line: 1, EndLine: len(lines), Scope: SummaryScopeFile, Content: lines[1 : len(lines)-1]}}
+
+	result, err := analyzer.Analyze([]FileStructure{mdFile})
+	require.NoError(t, err)
+	for _, action := range result.MdActions[mdFile.Path] {
+		assert.NotEqual(t, ActionSummary, action.Type)
+	}
+}
+
+func TestAnalyzer_Permalinks(t *testing.T) {
+	const (
+		oldHash = "0123456789abcdef0123456789abcdef01234567"
+		newHash = "fedcba9876543210fedcba9876543210fedcba98"
+	)
+
+	// Footnote refers to somefolder/somefile.go:15:impl
+	analyze := func(currentURL string, tagLine int) *AnalyzerResult {
+		mdFile := createMdStructureA("req.md", "pkg1", 10, "REQ001", CoverageStatusWordCovrd)
+		mdFile.CoverageFootnotes = []CoverageFootnote{
+			{
+				CoverageFootnoteId: "REQ001",
+				PackageId:          "pkg1",
+				Line:               20,
+				Coverers:           []Coverer{{CoverageLabel: "somefolder/somefile.go:15:impl", CoverageURL: currentURL}},
+			},
+		}
+		srcFile := createSourceFileStructure(
+			"somefolder/somefile.go",
+			"https://github.com/org/repo/blob/"+newHash,
+			[]CoverageTag{createCoverageTag(StrToReqId("pkg1/REQ001"), "impl", tagLine)},
+		)
+		result, err := NewAnalyzerEx(&AnalyzerConfig{Permalinks: true}).Analyze([]FileStructure{mdFile, srcFile})
+		require.NoError(t, err)
+		require.Empty(t, result.ProcessingErrors)
+		return result
+	}
+
+	t.Run("Same coverers, another commit", func(t *testing.T) {
+		result := analyze("https://github.com/org/repo/blob/"+oldHash+"/somefolder/somefile.go#L15", 15)
+		require.Empty(t, result.MdActions)
+	})
+
+	t.Run("Coverer line changed", func(t *testing.T) {
+		result := analyze("https://github.com/org/repo/blob/"+oldHash+"/somefolder/somefile.go#L15", 16)
+		require.Len(t, result.MdActions["req.md"], 1)
+		action := result.MdActions["req.md"][0]
+		require.Equal(t, ActionFootnote, action.Type)
+		require.Contains(t, action.Data, "https://github.com/org/repo/blob/"+newHash+"/somefolder/somefile.go#L16")
+	})
+
+	t.Run("Current URL is not pinned", func(t *testing.T) {
+		result := analyze("https://github.com/org/repo/blob/main/somefolder/somefile.go#L15", 15)
+		require.Len(t, result.MdActions["req.md"], 1)
+		require.Contains(t, result.MdActions["req.md"][0].Data, newHash)
+	})
+}
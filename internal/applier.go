@@ -5,24 +5,35 @@ package internal
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 )
 
+type ApplierConfig struct {
+	DryRun bool // make no changes to files
+	Diff   bool // print changes as a unified diff instead of the list of actions
+}
+
 type applier struct {
 	dryRun bool
+	diff   bool
 }
 
-func NewApplier(dryRun bool) IApplier {
+func NewApplier(acfg *ApplierConfig) IApplier {
 	return &applier{
-		dryRun: dryRun,
+		dryRun: acfg.DryRun,
+		diff:   acfg.Diff,
 	}
 }
 
-func (a *applier) Apply(ar *AnalyzerResult) error {
-	if a.dryRun || IsVerbose {
+func (a *applier) Apply(ctx context.Context, ar *AnalyzerResult) error {
+	if (a.dryRun || IsVerbose) && !a.diff {
 		for _, actions := range ar.MdActions {
 			for _, action := range actions {
 				fmt.Println("Action\n\t" + action.String())
@@ -30,23 +41,163 @@ func (a *applier) Apply(ar *AnalyzerResult) error {
 		}
 	}
 	if len(ar.MdActions) == 0 {
-		fmt.Println("reqmd: Nothing to do")
+		if !a.diff {
+			fmt.Println("reqmd: Nothing to do")
+		}
 		return nil
 	}
+
+	// Validate all actions and prepare the new content before any file is touched
+	changes, err := prepareMdFileChanges(ar.MdActions)
+	if err != nil {
+		return err
+	}
+
+	if a.diff {
+		if err := printMdFileChanges(changes); err != nil {
+			return err
+		}
+	}
+
 	if a.dryRun {
 		return nil
 	}
 
-	for path, actions := range ar.MdActions {
-		err := applyMdActions(path, actions)
+	// Committing is not interrupted, files are changed all or none
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return commitMdFileChanges(changes)
+}
+
+// printMdFileChanges prints changes as a patch, paths are relative to the current folder
+func printMdFileChanges(changes []*mdFileChange) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	for _, change := range changes {
+		relPath, err := filepath.Rel(wd, change.path)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to get relative path for %s: %w", change.path, err)
 		}
+		fmt.Print(UnifiedDiff(filepath.ToSlash(relPath), change.original, change.updated))
 	}
+	return nil
+}
 
+// sortedMdActionPaths returns paths of the files to be changed in lexical order
+func sortedMdActionPaths(mdActions map[FilePath][]MdAction) []FilePath {
+	paths := make([]FilePath, 0, len(mdActions))
+	for path := range mdActions {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// mdFileChange holds the original and the updated content of a markdown file
+type mdFileChange struct {
+	path     FilePath
+	mode     os.FileMode
+	original []byte
+	updated  []byte
+	tempPath string // staged content, empty if not staged yet
+}
+
+// prepareMdFileChanges applies actions in memory, files are processed in the order of their paths
+func prepareMdFileChanges(mdActions map[FilePath][]MdAction) ([]*mdFileChange, error) {
+	paths := sortedMdActionPaths(mdActions)
+	changes := make([]*mdFileChange, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := applyMdActions(path, original, mdActions[path])
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &mdFileChange{
+			path:     path,
+			mode:     info.Mode().Perm(),
+			original: original,
+			updated:  updated,
+		})
+	}
+	return changes, nil
+}
+
+// commitMdFileChanges writes the updated content of all files or none of them:
+//   - the updated content is staged in temp files next to the original files
+//   - temp files are renamed into place
+//   - if a rename fails, the files that are already replaced are restored
+func commitMdFileChanges(changes []*mdFileChange) (err error) {
+	defer func() {
+		for _, change := range changes {
+			if change.tempPath != "" {
+				_ = os.Remove(change.tempPath)
+			}
+		}
+	}()
+
+	for _, change := range changes {
+		if change.tempPath, err = writeTempFile(change.path, change.updated, change.mode); err != nil {
+			return fmt.Errorf("failed to stage changes for %s: %w", change.path, err)
+		}
+	}
+
+	for i, change := range changes {
+		if err = os.Rename(change.tempPath, change.path); err != nil {
+			err = fmt.Errorf("failed to replace %s: %w", change.path, err)
+			return errors.Join(err, restoreMdFiles(changes[:i]))
+		}
+		change.tempPath = ""
+	}
 	return nil
 }
 
+// restoreMdFiles restores the original content of the files that are already replaced
+func restoreMdFiles(changes []*mdFileChange) error {
+	var errs []error
+	for _, change := range changes {
+		tempPath, err := writeTempFile(change.path, change.original, change.mode)
+		if err == nil {
+			err = os.Rename(tempPath, change.path)
+		}
+		if err != nil {
+			_ = os.Remove(tempPath)
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", change.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeTempFile writes content to a new temp file in the same folder as path, so that the temp file can be renamed to path
+func writeTempFile(path string, content []byte, mode os.FileMode) (tempPath string, err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".reqmd-*")
+	if err != nil {
+		return "", err
+	}
+	tempPath = f.Name()
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, mode)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return "", err
+	}
+	return tempPath, nil
+}
+
 /*
 Principles:
 
@@ -55,22 +206,28 @@ Principles:
 
 */
 
-func applyMdActions(path FilePath, actions []MdAction) error {
-	lines, hasCRLF, err := readFilePreserveEndings(string(path))
-	if err != nil {
-		return err
-	}
+// applyMdActions applies actions to the content of the file and returns the new content
+func applyMdActions(path FilePath, content []byte, actions []MdAction) ([]byte, error) {
+	lines, hasCRLF := splitLinesPreserveEndings(content)
 
 	// Trim trailing empty lines
 	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
 		lines = lines[:len(lines)-1]
 	}
 
+	var err error
+	var regionActions []MdAction
+	var newFootnotes []MdAction
 	for _, action := range actions {
+		if action.Type == ActionSummary || action.Type == ActionFootnoteRemove {
+			// Regions are replaced after all other actions, since replacement can change the number of lines
+			regionActions = append(regionActions, action)
+			continue
+		}
 		if action.Line > 0 {
 			lineIndex := action.Line - 1
 			if lineIndex < 0 || lineIndex >= len(lines) {
-				return fmt.Errorf("line %d doesn't exist in file %s", action.Line, path)
+				return nil, fmt.Errorf("line %d doesn't exist in file %s", action.Line, path)
 			}
 			line := lines[lineIndex]
 			var re *regexp.Regexp
@@ -80,10 +237,10 @@ func applyMdActions(path FilePath, actions []MdAction) error {
 			case ActionFootnote:
 				re = CoverageFootnoteRegex
 			default:
-				return fmt.Errorf("unknown action type: %s", action.Type)
+				return nil, fmt.Errorf("unknown action type: %s", action.Type)
 			}
 			if !re.MatchString(line) {
-				return fmt.Errorf("line %d does not match requirement Id in file %s", action.Line, path)
+				return nil, fmt.Errorf("line %d does not match requirement Id in file %s", action.Line, path)
 			}
 			newLine := re.ReplaceAllStringFunc(line, func(_ string) string {
 				return action.Data
@@ -92,32 +249,86 @@ func applyMdActions(path FilePath, actions []MdAction) error {
 
 		} else {
 			if action.Type != ActionFootnote {
-				return fmt.Errorf("invalid action type for line=0 in file %s", path)
-			}
-			if needFootnoteSeparator(lines) {
-				lines = append(lines, "")
+				return nil, fmt.Errorf("invalid action type for line=0 in file %s", path)
 			}
-			lines = append(lines, action.Data)
+			// New footnotes are appended after regions are replaced, so they follow the remaining footnotes
+			newFootnotes = append(newFootnotes, action)
 		}
 	}
 
+	// Replace regions bottom-up so that line numbers of the remaining regions stay valid
+	sort.Slice(regionActions, func(i, j int) bool {
+		return regionActions[i].Line > regionActions[j].Line
+	})
+	for _, action := range regionActions {
+		if action.Type == ActionFootnoteRemove {
+			lines, err = removeFootnoteLine(path, lines, action)
+		} else {
+			lines, err = replaceSummaryBlock(path, lines, action)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Removed footnotes can leave the separator behind
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for _, action := range newFootnotes {
+		if needFootnoteSeparator(lines) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, action.Data)
+	}
+
 	// if last line is not empty, add an empty line
 	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
 		lines = append(lines, "")
 	}
 
-	if err := writeFilePreserveEndings(string(path), lines, hasCRLF); err != nil {
-		return err
+	return joinLinesPreserveEndings(lines, hasCRLF), nil
+}
+
+// removeFootnoteLine removes the line action.Line that shall be a CoverageFootnote.
+// Lines below action.Line can be removed as well, so actions shall be applied bottom-up
+func removeFootnoteLine(path FilePath, lines []string, action MdAction) ([]string, error) {
+	lineIndex := action.Line - 1
+	if lineIndex < 0 || lineIndex >= len(lines) {
+		return nil, fmt.Errorf("line %d doesn't exist in file %s", action.Line, path)
 	}
-	return nil
+	if !CoverageFootnoteRegex.MatchString(lines[lineIndex]) {
+		return nil, fmt.Errorf("line %d does not match coverage footnote in file %s", action.Line, path)
+	}
+	lines = append(lines[:lineIndex], lines[lineIndex+1:]...)
+	// Do not leave two empty lines in place of a removed block
+	if lineIndex > 0 && lineIndex < len(lines) && strings.TrimSpace(lines[lineIndex-1]) == "" && strings.TrimSpace(lines[lineIndex]) == "" {
+		lines = append(lines[:lineIndex], lines[lineIndex+1:]...)
+	}
+	return lines, nil
 }
 
-// readFilePreserveEndings reads a file, detects if it uses CRLF, and returns lines without stripping end-of-line markers.
-func readFilePreserveEndings(filePath string) ([]string, bool, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, false, err
+// replaceSummaryBlock replaces lines action.Line..action.EndLine with action.Data
+func replaceSummaryBlock(path FilePath, lines []string, action MdAction) ([]string, error) {
+	begin, end := action.Line-1, action.EndLine-1
+	if begin < 0 || end < begin || end >= len(lines) {
+		return nil, fmt.Errorf("lines %d..%d don't exist in file %s", action.Line, action.EndLine, path)
+	}
+	if !SummaryBeginMarkerRegex.MatchString(lines[begin]) {
+		return nil, fmt.Errorf("line %d does not match summary begin marker in file %s", action.Line, path)
+	}
+	if end > begin && !SummaryEndMarkerRegex.MatchString(lines[end]) {
+		return nil, fmt.Errorf("line %d does not match summary end marker in file %s", action.EndLine, path)
 	}
+	res := make([]string, 0, len(lines))
+	res = append(res, lines[:begin]...)
+	res = append(res, strings.Split(action.Data, "\n")...)
+	return append(res, lines[end+1:]...), nil
+}
+
+// splitLinesPreserveEndings detects if the content uses CRLF and returns lines without end-of-line markers.
+func splitLinesPreserveEndings(content []byte) ([]string, bool) {
 	hasCRLF := bytes.Contains(content, []byte("\r\n"))
 
 	var lines []string
@@ -126,17 +337,16 @@ func readFilePreserveEndings(filePath string) ([]string, bool, error) {
 	} else {
 		lines = strings.Split(string(content), "\n")
 	}
-	return lines, hasCRLF, nil
+	return lines, hasCRLF
 }
 
-// writeFilePreserveEndings joins lines with CRLF or LF depending on hasCRLF and writes them back to disk.
-func writeFilePreserveEndings(filePath string, lines []string, hasCRLF bool) error {
+// joinLinesPreserveEndings joins lines with CRLF or LF depending on hasCRLF.
+func joinLinesPreserveEndings(lines []string, hasCRLF bool) []byte {
 	delim := "\n"
 	if hasCRLF {
 		delim = "\r\n"
 	}
-	out := strings.Join(lines, delim)
-	return os.WriteFile(filePath, []byte(out), 0644)
+	return []byte(strings.Join(lines, delim))
 }
 
 // needFootnoteSeparator checks if we must insert an empty line before the first appended footnote.
@@ -0,0 +1,96 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const applierTestMd = "---\nreqmd.package: pkg1\n---\n\n`~REQ001~`\n"
+
+// An invalid action in one file shall leave all files untouched
+func TestApplier_Apply_AllOrNothing(t *testing.T) {
+	dir := t.TempDir()
+	path1 := filepath.Join(dir, "1.md")
+	path2 := filepath.Join(dir, "2.md")
+	require.NoError(t, os.WriteFile(path1, []byte(applierTestMd), 0644))
+	require.NoError(t, os.WriteFile(path2, []byte(applierTestMd), 0644))
+
+	ar := &AnalyzerResult{
+		MdActions: map[FilePath][]MdAction{
+			path1: {{Type: ActionSite, Path: path1, Line: 5, Data: FormatRequirementSite("REQ001", CoverageStatusWordUncvrd, "1")}},
+			path2: {{Type: ActionSite, Path: path2, Line: 100, Data: FormatRequirementSite("REQ001", CoverageStatusWordUncvrd, "1")}},
+		},
+	}
+
+	err := NewApplier(&ApplierConfig{}).Apply(t.Context(), ar)
+	require.ErrorContains(t, err, "line 100 doesn't exist")
+
+	for _, path := range []string{path1, path2} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, applierTestMd, string(content))
+	}
+	requireNoTempFiles(t, dir)
+}
+
+func TestApplier_Apply(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1.md")
+	require.NoError(t, os.WriteFile(path, []byte(applierTestMd), 0600))
+
+	ar := &AnalyzerResult{
+		MdActions: map[FilePath][]MdAction{
+			path: {
+				{Type: ActionSite, Path: path, Line: 5, Data: FormatRequirementSite("REQ001", CoverageStatusWordUncvrd, "1")},
+				{Type: ActionFootnote, Path: path, Data: "[^1]: `[~pkg1/REQ001~impl]`"},
+			},
+		},
+	}
+	require.NoError(t, NewApplier(&ApplierConfig{}).Apply(t.Context(), ar))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "---\nreqmd.package: pkg1\n---\n\n`~REQ001~`uncvrd[^1]❓\n\n[^1]: `[~pkg1/REQ001~impl]`\n", string(content))
+
+	// File mode is preserved
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	requireNoTempFiles(t, dir)
+}
+
+// Files that are already replaced shall be restored if a later file cannot be replaced
+func TestApplier_commitMdFileChanges_Restore(t *testing.T) {
+	dir := t.TempDir()
+	path1 := filepath.Join(dir, "1.md")
+	require.NoError(t, os.WriteFile(path1, []byte("original"), 0644))
+
+	// Renaming a file over a non-empty folder fails
+	path2 := filepath.Join(dir, "2.md")
+	require.NoError(t, os.MkdirAll(filepath.Join(path2, "sub"), 0755))
+
+	changes := []*mdFileChange{
+		{path: path1, mode: 0644, original: []byte("original"), updated: []byte("updated")},
+		{path: path2, mode: 0644, original: []byte("original"), updated: []byte("updated")},
+	}
+	err := commitMdFileChanges(changes)
+	require.ErrorContains(t, err, "failed to replace")
+
+	content, err := os.ReadFile(path1)
+	require.NoError(t, err)
+	require.Equal(t, "original", string(content))
+	requireNoTempFiles(t, dir)
+}
+
+func requireNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.reqmd-*"))
+	require.NoError(t, err)
+	require.Empty(t, matches)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Baseline file that is looked up in the current folder if --baseline is not specified
+const defaultBaselineFileName = ".reqmd-baseline.json"
+
+// Baseline is the set of known ProcessingErrors that do not fail processing, ref. docs/op-baseline.md
+type Baseline struct {
+	Errors []BaselineEntry `json:"errors"`
+}
+
+// BaselineEntry is keyed by the content of the line instead of the line number, so it survives line shifts
+type BaselineEntry struct {
+	Code        string `json:"code"`
+	File        string `json:"file"`        // slashed path relative to the current folder
+	Fingerprint string `json:"fingerprint"` // hash of the trimmed line of the error, ref. errorFingerprint()
+}
+
+func (e *BaselineEntry) key() string {
+	return e.Code + "\x00" + e.File + "\x00" + e.Fingerprint
+}
+
+// NewBaseline creates the baseline of errs, entries are sorted by file, code and fingerprint
+func NewBaseline(errs []ProcessingError) (*Baseline, error) {
+	fp := newFingerprinter()
+	b := &Baseline{Errors: []BaselineEntry{}}
+	for _, e := range errs {
+		entry, err := fp.entry(&e)
+		if err != nil {
+			return nil, err
+		}
+		b.Errors = append(b.Errors, entry)
+	}
+	sort.Slice(b.Errors, func(i, j int) bool {
+		ei, ej := b.Errors[i], b.Errors[j]
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		}
+		if ei.Code != ej.Code {
+			return ei.Code < ej.Code
+		}
+		return ei.Fingerprint < ej.Fingerprint
+	})
+	return b, nil
+}
+
+// LoadBaseline reads the baseline file.
+// If path is empty, defaultBaselineFileName is used and nil is returned if it does not exist.
+func LoadBaseline(path string) (*Baseline, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultBaselineFileName
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file %s: %w", path, err)
+	}
+	Verbose("Baseline loaded", "path", path, "errors", len(b.Errors))
+	return b, nil
+}
+
+// Marshal returns the content of the baseline file
+func (b *Baseline) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// split splits errs into errors that are not in the baseline and baselined ones.
+// Every entry matches one error, so new errors on lines equal to baselined ones are reported.
+func (b *Baseline) split(errs []ProcessingError) (reported, baselined []ProcessingError) {
+	if b == nil || len(b.Errors) == 0 {
+		return errs, nil
+	}
+	entries := make(map[string]int, len(b.Errors))
+	for _, entry := range b.Errors {
+		entries[entry.key()]++
+	}
+	fp := newFingerprinter()
+	for _, e := range errs {
+		entry, err := fp.entry(&e)
+		if err == nil && entries[entry.key()] > 0 {
+			entries[entry.key()]--
+			baselined = append(baselined, e)
+			continue
+		}
+		reported = append(reported, e)
+	}
+	return reported, baselined
+}
+
+// fingerprinter reads lines of files with errors once
+type fingerprinter struct {
+	wd    string
+	lines map[string][]string
+}
+
+func newFingerprinter() *fingerprinter {
+	wd, _ := os.Getwd()
+	return &fingerprinter{wd: wd, lines: make(map[string][]string)}
+}
+
+func (f *fingerprinter) entry(e *ProcessingError) (BaselineEntry, error) {
+	lines, ok := f.lines[e.FilePath]
+	if !ok {
+		content, err := os.ReadFile(e.FilePath)
+		if err != nil {
+			return BaselineEntry{}, err
+		}
+		lines, _ = splitLinesPreserveEndings(content)
+		f.lines[e.FilePath] = lines
+	}
+	line := ""
+	if e.Line > 0 && e.Line <= len(lines) {
+		line = lines[e.Line-1]
+	}
+
+	file := e.FilePath
+	if abs, err := filepath.Abs(file); err == nil {
+		if rel, err := filepath.Rel(f.wd, abs); err == nil {
+			file = rel
+		}
+	}
+	return BaselineEntry{
+		Code:        e.Code,
+		File:        filepath.ToSlash(file),
+		Fingerprint: errorFingerprint(line),
+	}, nil
+}
+
+// errorFingerprint returns the hash of the line of the error, leading and trailing spaces are ignored
+func errorFingerprint(line string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(line)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// collectProcessingErrors scans and analyzes paths and returns all errors that are not suppressed.
+// Unlike ITracer, files are analyzed even if there are syntax errors
+func collectProcessingErrors(ctx context.Context, scanner IScanner, analyzer IAnalyzer, paths []string) ([]ProcessingError, error) {
+	absolutePaths := make([]string, len(paths))
+	for i, path := range paths {
+		absolutePath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+		absolutePaths[i] = absolutePath
+	}
+
+	scanResult, err := scanner.Scan(ctx, absolutePaths)
+	if err != nil {
+		return nil, err
+	}
+	analyzeResult, err := analyzer.Analyze(scanResult.Files)
+	if err != nil {
+		return nil, err
+	}
+	return append(scanResult.ProcessingErrors, analyzeResult.ProcessingErrors...), nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "req.md")
+	scan := func() []ProcessingError {
+		_, errs, err := parseFile(&ScannerContext{}, path)
+		require.NoError(t, err)
+		return errs
+	}
+
+	writeMd(t, dir, "req.md", "- `~a-b~`\n- `~A~` `~B~`\n")
+	errs := scan()
+	require.Len(t, errs, 2)
+
+	baseline, err := NewBaseline(errs)
+	require.NoError(t, err)
+	require.Len(t, baseline.Errors, 2)
+	require.Equal(t, "multisites", baseline.Errors[0].Code)
+	require.Equal(t, "reqident", baseline.Errors[1].Code)
+
+	// Round trip
+	data, err := baseline.Marshal()
+	require.NoError(t, err)
+	baselinePath := filepath.Join(dir, defaultBaselineFileName)
+	require.NoError(t, os.WriteFile(baselinePath, data, 0644))
+	baseline, err = LoadBaseline(baselinePath)
+	require.NoError(t, err)
+	require.Len(t, baseline.Errors, 2)
+
+	// Entries survive line shifts, new errors are reported even if their lines are equal to baselined ones
+	writeMd(t, dir, "req.md", "Intro\n\n  - `~A~` `~B~`\n- `~a-b~`\n- `~a-b~`\n")
+	reported, baselined := baseline.split(scan())
+	require.Len(t, baselined, 2)
+	require.Len(t, reported, 1)
+	require.Equal(t, "reqident", reported[0].Code)
+	require.Equal(t, 9, reported[0].Line)
+
+	// No baseline
+	reported, baselined = (*Baseline)(nil).split(errs)
+	require.Equal(t, errs, reported)
+	require.Empty(t, baselined)
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	// Default file is optional, explicit one is required
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	baseline, err := LoadBaseline("")
+	require.NoError(t, err)
+	require.Nil(t, baseline)
+
+	_, err = LoadBaseline("missing.json")
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(defaultBaselineFileName, []byte("{"), 0644))
+	_, err = LoadBaseline("")
+	require.ErrorContains(t, err, "failed to parse baseline file")
+}
+
+func TestTracer_Baseline(t *testing.T) {
+	dir := t.TempDir()
+	path := writeMd(t, dir, "req.md", "- `~A~` `~B~`\n- `~C~`\n")
+
+	trace := func(baseline *Baseline) error {
+		return traceDir(t, &ScannerConfig{NoVCS: true, Baseline: baseline}, &AnalyzerConfig{Baseline: baseline}, dir)
+	}
+
+	errs, err := collectProcessingErrors(t.Context(), NewScanner(&ScannerConfig{NoVCS: true}), NewAnalyzer(), []string{dir})
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+
+	require.Error(t, trace(nil))
+
+	baseline, err := NewBaseline(errs)
+	require.NoError(t, err)
+	require.NoError(t, trace(baseline))
+	updated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(updated), "- `~C~`uncvrd[^1]❓\n")
+}
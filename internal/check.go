@@ -0,0 +1,64 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// NewCheckApplier returns IApplier that reports actions instead of applying them and fails if there are any, e.g. for CI
+func NewCheckApplier() IApplier {
+	return &checkApplier{}
+}
+
+type checkApplier struct{}
+
+func (a *checkApplier) Apply(_ context.Context, ar *AnalyzerResult) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	issues := 0
+	for _, path := range sortedMdActionPaths(ar.MdActions) {
+		actions := append([]MdAction(nil), ar.MdActions[path]...)
+		sort.SliceStable(actions, func(i, j int) bool {
+			return actions[i].Line < actions[j].Line
+		})
+		relPath := path
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			relPath = filepath.ToSlash(rel)
+		}
+		for _, action := range actions {
+			fmt.Printf("%s:%d: %s\n", relPath, action.Line, describeMdAction(&action))
+			issues++
+		}
+	}
+	if issues > 0 {
+		return fmt.Errorf("reqmd: %d issue(s) found, run reqmd trace to fix them", issues)
+	}
+	return nil
+}
+
+// describeMdAction returns the issue that is fixed by the action
+func describeMdAction(action *MdAction) string {
+	switch action.Type {
+	case ActionSite:
+		return "requirement site is not up to date: " + string(action.RequirementName)
+	case ActionFootnote:
+		if action.Line == 0 {
+			return "coverage footnote is missing: " + string(action.RequirementName)
+		}
+		return "coverage footnote is not up to date: " + string(action.RequirementName)
+	case ActionFootnoteRemove:
+		return "coverage footnote is orphaned: " + string(action.RequirementName)
+	case ActionSummary:
+		return "summary block is not up to date"
+	}
+	return string(action.Type)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckApplier(t *testing.T) {
+	applier := NewCheckApplier()
+
+	require.NoError(t, applier.Apply(t.Context(), &AnalyzerResult{MdActions: map[FilePath][]MdAction{}}))
+
+	err := applier.Apply(t.Context(), &AnalyzerResult{MdActions: map[FilePath][]MdAction{
+		"req.md": {
+			{Type: ActionFootnoteRemove, Path: "req.md", Line: 20, RequirementName: "REQ002"},
+			{Type: ActionSite, Path: "req.md", Line: 10, RequirementName: "REQ001"},
+		},
+	}})
+	require.EqualError(t, err, "reqmd: 2 issue(s) found, run reqmd trace to fix them")
+}
+
+func TestDescribeMdAction(t *testing.T) {
+	tests := []struct {
+		action MdAction
+		want   string
+	}{
+		{MdAction{Type: ActionSite, RequirementName: "REQ001"}, "requirement site is not up to date: REQ001"},
+		{MdAction{Type: ActionFootnote, RequirementName: "REQ001"}, "coverage footnote is missing: REQ001"},
+		{MdAction{Type: ActionFootnote, Line: 5, RequirementName: "REQ001"}, "coverage footnote is not up to date: REQ001"},
+		{MdAction{Type: ActionFootnoteRemove, Line: 5, RequirementName: "REQ002"}, "coverage footnote is orphaned: REQ002"},
+		{MdAction{Type: ActionSummary, Line: 5}, "summary block is not up to date"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, describeMdAction(&tt.action))
+	}
+}
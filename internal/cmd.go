@@ -4,9 +4,13 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"runtime/debug"
+	"time"
 
 	_ "embed"
 
@@ -17,16 +21,49 @@ import (
 var Version string
 
 func ExecRootCmd(args []string, ver string) error {
+	return ExecRootCmdEx(context.Background(), args, ver)
+}
+
+// ExecRootCmdEx executes the command, processing stops when ctx is done, e.g. on interrupt
+func ExecRootCmdEx(ctx context.Context, args []string, ver string) error {
 	rootCmd := prepareRootCmd(
 		"reqmd",
 		"Requirements processor",
 		args,
 		ver,
 		newTraceCmd(),
+		newCheckCmd(),
+		newFmtCmd(),
+		newFixCmd(),
+		newBaselineCmd(),
+		newExplainCmd(),
+		newHookCmd(),
+		newInstallHookCmd(),
+		newMergeDriverCmd(),
+		newResolveCmd(),
 		newVersionCmd(),
 	)
 
-	err := rootCmd.Execute()
+	var timeout time.Duration
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Maximum duration of processing, e.g. 30s or 5m (default no limit)")
+	cancel := context.CancelFunc(func() {})
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if timeout > 0 {
+			var timeoutCtx context.Context
+			timeoutCtx, cancel = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(timeoutCtx)
+		}
+		return nil
+	}
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancel()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		err = fmt.Errorf("reqmd: --timeout %s exceeded: %w", timeout, err)
+	case errors.Is(err, context.Canceled):
+		err = fmt.Errorf("reqmd: interrupted: %w", err)
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 	}
@@ -61,11 +98,135 @@ func prepareRootCmd(use, short string, args []string, ver string, cmds ...*cobra
 	return rootCmd
 }
 
+// ignoreFlags are flags of the commands that parse markdown files
+type ignoreFlags struct {
+	ignoreLines []string
+}
+
+func (f *ignoreFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.ignoreLines, "ignore-lines", nil, "Regular expression pattern for lines to ignore. Can be specified multiple times.")
+}
+
+// processFlags are flags of the commands that scan files and report ProcessingErrors
+type processFlags struct {
+	ignoreFlags
+	configPath string
+	baseline   string
+	maxErrors  int
+	failFast   bool
+}
+
+func (f *processFlags) register(cmd *cobra.Command) {
+	f.ignoreFlags.register(cmd)
+	cmd.Flags().StringVar(&f.configPath, "config", "", "Path to the configuration file (default .reqmd.json in the current folder, if exists)")
+	cmd.Flags().IntVar(&f.maxErrors, "max-errors", defaultMaxErrors, "Maximum number of reported errors of reading files, 0 means no limit")
+	cmd.Flags().BoolVar(&f.failFast, "fail-fast", false, "Stop scanning at the first error of reading folders and files")
+	cmd.Flags().StringVar(&f.baseline, "baseline", "", "Path to the baseline file with known errors (default .reqmd-baseline.json in the current folder, if exists)")
+}
+
+// load validates paths and returns ignore patterns, the configuration and the baseline
+func (f *processFlags) load(paths []string) ([]*regexp.Regexp, *Config, *Baseline, error) {
+	// Validate all paths exist
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil, nil, fmt.Errorf("path does not exist: %s", path)
+		}
+	}
+
+	patterns, err := preparePatterns(f.ignoreLines)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	config, err := LoadConfig(f.configPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	baseline, err := LoadBaseline(f.baseline)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return patterns, config, baseline, nil
+}
+
+// scanFlags are flags of the commands that scan and analyze files
+type scanFlags struct {
+	processFlags
+	extensions  string
+	typeList    string
+	ref         string
+	remote      string
+	permalinks  bool
+	footnoteIds string
+}
+
+func (f *scanFlags) register(cmd *cobra.Command) {
+	// git/gh style of the usage string
+	cmd.Flags().StringVarP(&f.extensions, "extensions", "e", "", "Comma-separated list of source file extensions to process (e.g. .go,.ts,.js)")
+	f.processFlags.register(cmd)
+	cmd.Flags().StringVar(&f.typeList, "types", "", "Comma-separated list of requirement types (e.g. it,cmp,utest)")
+	cmd.Flags().StringVar(&f.ref, "ref", "", "Commit ref (branch, tag or commit hash) used in file URLs (default is the default branch of the remote)")
+	cmd.Flags().BoolVar(&f.permalinks, "permalinks", false, "Pin coverage URLs to the HEAD commit hash")
+	cmd.Flags().StringVar(&f.remote, "remote", defaultRemoteName, "Name of the git remote used to construct file URLs")
+	cmd.Flags().StringVar(&f.footnoteIds, "footnote-ids", "", "Ids of new footnotes: numeric or name (default numeric or footnoteIds of the configuration file)")
+}
+
+// configs validates paths and flags and returns configurations of the scanner and the analyzer
+func (f *scanFlags) configs(paths []string) (*ScannerConfig, *AnalyzerConfig, error) {
+	patterns, config, baseline, err := f.load(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if f.permalinks && f.ref != "" {
+		return nil, nil, fmt.Errorf("--permalinks and --ref can not be used together")
+	}
+
+	footnoteIds, err := config.FootnoteIdStrategy(f.footnoteIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcfg := config.GitConfig()
+	gcfg.Ref = f.ref
+	gcfg.Remote = f.remote
+	gcfg.Permalinks = f.permalinks
+
+	scfg := &ScannerConfig{
+		Extensions:        f.extensions,
+		IgnorePatterns:    patterns,
+		GitConfig:         gcfg,
+		RepairStatusEmoji: true,
+		Severities:        config.Severities,
+		Baseline:          baseline,
+		MaxErrors:         f.maxErrors,
+		FailFast:          f.failFast,
+	}
+	if f.typeList != "" {
+		types, err := ParseTypeList(f.typeList)
+		if err != nil {
+			return nil, nil, err
+		}
+		scfg.TypeRegistry = NewTypeRegistry(types)
+	}
+
+	return scfg, &AnalyzerConfig{
+		Permalinks:  f.permalinks,
+		FootnoteIds: footnoteIds,
+		Severities:  config.Severities,
+		Baseline:    baseline,
+	}, nil
+}
+
 func newTraceCmd() *cobra.Command {
-	var extensions string
+	var sf scanFlags
 	var dryRun bool
-	var ignoreLines []string
-	var typeList string
+	var diff bool
+	var noVCS bool
+	var baseURL string
+	var includeUntracked bool
+	var staged bool
 
 	cmd := &cobra.Command{
 		Use:           "trace [flags] <paths>...",
@@ -77,45 +238,268 @@ func newTraceCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			paths := args
 
-			// Validate all paths exist
+			if baseURL != "" && !noVCS {
+				return fmt.Errorf("--base-url requires --no-vcs")
+			}
+			if noVCS && (sf.permalinks || sf.ref != "" || includeUntracked || staged) {
+				return fmt.Errorf("--no-vcs can not be used with --permalinks, --ref, --include-untracked or --staged")
+			}
+
+			scfg, acfg, err := sf.configs(paths)
+			if err != nil {
+				return err
+			}
+			scfg.GitConfig.IncludeUntracked = includeUntracked
+			scfg.GitConfig.Staged = staged
+			scfg.NoVCS = noVCS
+			scfg.BaseURL = baseURL
+
+			newAnalyzer := func() IAnalyzer { return NewAnalyzerEx(acfg) }
+
+			scanner := NewScanner(scfg)
+			analyzer := newAnalyzer()
+			applier := NewApplier(&ApplierConfig{
+				DryRun: dryRun,
+				Diff:   diff,
+			})
+
+			var verifier IVerifier
+			if !dryRun {
+				verifier = NewVerifier(scfg, newAnalyzer)
+			}
+
+			tracer := NewTracer(scanner, analyzer, applier, verifier, paths)
+
+			return tracer.Trace(cmd.Context())
+		},
+	}
+
+	sf.register(cmd)
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done, but make no changes to files")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print changes as a unified diff that can be applied by git apply")
+	cmd.Flags().BoolVar(&includeUntracked, "include-untracked", false, "Process files that are not committed, except ignored ones")
+	cmd.Flags().BoolVar(&staged, "staged", false, "Read file hashes from the git index, so staged files are processed")
+	cmd.Flags().BoolVar(&noVCS, "no-vcs", false, "Process paths that are not git repositories, all files are treated as tracked")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "Base URL or URL template with {path} and {line} for --no-vcs (default relative links)")
+
+	return cmd
+}
+
+func newCheckCmd() *cobra.Command {
+	var sf scanFlags
+
+	cmd := &cobra.Command{
+		Use:           "check [flags] <paths>...",
+		Short:         "Check that markdown files are up to date, make no changes",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+
+			scfg, acfg, err := sf.configs(paths)
+			if err != nil {
+				return err
+			}
+			// Mismatched CoverageStatusEmojis are reported as syntax errors
+			scfg.RepairStatusEmoji = false
+
+			scanner := NewScanner(scfg)
+			analyzer := NewAnalyzerEx(acfg)
+
+			return NewTracer(scanner, analyzer, NewCheckApplier(), nil, paths).Trace(cmd.Context())
+		},
+	}
+
+	sf.register(cmd)
+
+	return cmd
+}
+
+func newFmtCmd() *cobra.Command {
+	var pf processFlags
+	var dryRun bool
+	var diff bool
+	var footnoteIdsFlag string
+
+	cmd := &cobra.Command{
+		Use:           "fmt [flags] <paths>...",
+		Short:         "Renumber coverage footnotes in order of requirement sites and move them to the end of markdown files",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			patterns, config, baseline, err := pf.load(paths)
+			if err != nil {
+				return err
+			}
+			footnoteIds, err := config.FootnoteIdStrategy(footnoteIdsFlag)
+			if err != nil {
+				return err
+			}
+			newFormatter := func() IAnalyzer { return NewFormatterEx(&AnalyzerConfig{FootnoteIds: footnoteIds}) }
+
+			// Only markdown files are processed, file URLs are not needed
+			scfg := &ScannerConfig{
+				Extensions:        markdownExtension,
+				IgnorePatterns:    patterns,
+				NoVCS:             true,
+				RepairStatusEmoji: true,
+				Severities:        config.Severities,
+				Baseline:          baseline,
+				MaxErrors:         pf.maxErrors,
+				FailFast:          pf.failFast,
+			}
+
+			scanner := NewScanner(scfg)
+			applier := NewApplier(&ApplierConfig{
+				DryRun: dryRun,
+				Diff:   diff,
+			})
+
+			var verifier IVerifier
+			if !dryRun {
+				verifier = NewVerifier(scfg, newFormatter)
+			}
+
+			return NewTracer(scanner, newFormatter(), applier, verifier, paths).Trace(cmd.Context())
+		},
+	}
+
+	pf.register(cmd)
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done, but make no changes to files")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print changes as a unified diff that can be applied by git apply")
+	cmd.Flags().StringVar(&footnoteIdsFlag, "footnote-ids", "", "Footnote ids: numeric or name, existing ids are converted (default numeric or footnoteIds of the configuration file)")
+
+	return cmd
+}
+
+func newFixCmd() *cobra.Command {
+	var inf ignoreFlags
+	var dryRun bool
+	var diff bool
+
+	cmd := &cobra.Command{
+		Use:           "fix [flags] <paths>...",
+		Short:         "Fix errors in markdown files that can be fixed safely and suggest fixes for other errors",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
 			for _, path := range paths {
 				if _, err := os.Stat(path); os.IsNotExist(err) {
 					return fmt.Errorf("path does not exist: %s", path)
 				}
 			}
 
-			patterns, err := preparePatterns(ignoreLines)
+			patterns, err := preparePatterns(inf.ignoreLines)
 			if err != nil {
 				return err
 			}
 
-			scfg := &ScannerConfig{
-				Extensions:     extensions,
+			suggestions, err := FixFiles(&FixConfig{
 				IgnorePatterns: patterns,
+				DryRun:         dryRun,
+				Diff:           diff,
+			}, paths, os.Stdout, os.Stderr)
+			if err != nil {
+				return err
 			}
-			if typeList != "" {
-				types, err := ParseTypeList(typeList)
-				if err != nil {
-					return err
-				}
-				scfg.TypeRegistry = NewTypeRegistry(types)
+			if suggestions > 0 {
+				return fmt.Errorf("reqmd: %d issue(s) can not be fixed automatically, ref. suggestions", suggestions)
 			}
+			return nil
+		},
+	}
 
-			scanner := NewScanner(scfg)
-			analyzer := NewAnalyzer()
-			applier := NewApplier(dryRun)
+	inf.register(cmd)
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done, but make no changes to files")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print changes as a unified diff that can be applied by git apply")
 
-			tracer := NewTracer(scanner, analyzer, applier, paths)
+	return cmd
+}
+
+func newBaselineCmd() *cobra.Command {
+	var sf scanFlags
+	var writePath string
+
+	cmd := &cobra.Command{
+		Use:           "baseline [flags] <paths>...",
+		Short:         "Record current processing errors, so that only new errors fail processing",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
 
-			return tracer.Trace()
+			scfg, acfg, err := sf.configs(paths)
+			if err != nil {
+				return err
+			}
+			// Errors of the existing baseline are recorded again
+			scfg.Baseline, acfg.Baseline = nil, nil
+			// Errors that are reported by reqmd check are recorded as well
+			scfg.RepairStatusEmoji = false
+
+			errs, err := collectProcessingErrors(cmd.Context(), NewScanner(scfg), NewAnalyzerEx(acfg), paths)
+			if err != nil {
+				return err
+			}
+			baseline, err := NewBaseline(errs)
+			if err != nil {
+				return err
+			}
+			data, err := baseline.Marshal()
+			if err != nil {
+				return err
+			}
+
+			if writePath == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(writePath, data, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("reqmd: %d error(s) are recorded in %s\n", len(baseline.Errors), writePath)
+			return nil
 		},
 	}
 
-	// git/gh style of the usage string
-	cmd.Flags().StringVarP(&extensions, "extensions", "e", "", "Comma-separated list of source file extensions to process (e.g. .go,.ts,.js)")
-	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done, but make no changes to files")
-	cmd.Flags().StringArrayVar(&ignoreLines, "ignore-lines", nil, "Regular expression pattern for lines to ignore. Can be specified multiple times.")
-	cmd.Flags().StringVar(&typeList, "types", "", "Comma-separated list of requirement types (e.g. it,cmp,utest)")
+	sf.register(cmd)
+	cmd.Flags().StringVar(&writePath, "write", "", "Write the baseline to the file, e.g. .reqmd-baseline.json (default is stdout)")
+
+	return cmd
+}
 
+func newExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "explain [<code>]",
+		Short:         "Explain the error code, list all codes if no code is given",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var text string
+			var err error
+			if len(args) == 0 {
+				text, err = ExplainCodes()
+			} else {
+				text, err = ExplainCode(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Print(text)
+			return nil
+		},
+	}
 	return cmd
 }
@@ -0,0 +1,86 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Paths that are processed by hooks if no paths are specified
+var defaultHookPaths = []string{"."}
+
+func newHookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Run reqmd as a git hook",
+	}
+	cmd.AddCommand(newPreCommitHookCmd())
+	return cmd
+}
+
+func newPreCommitHookCmd() *cobra.Command {
+	var sf scanFlags
+
+	cmd := &cobra.Command{
+		Use:           preCommitHookName + " [flags] [<paths>...]",
+		Short:         "Trace requirements using staged content and stage updated markdown files",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if len(paths) == 0 {
+				paths = defaultHookPaths
+			}
+
+			scfg, acfg, err := sf.configs(paths)
+			if err != nil {
+				return err
+			}
+			scfg.GitConfig.StagedContent = true
+
+			newAnalyzer := func() IAnalyzer { return NewAnalyzerEx(acfg) }
+
+			scanner := NewScanner(scfg)
+			applier := NewStagingApplier(NewApplier(&ApplierConfig{}))
+			verifier := NewVerifier(scfg, newAnalyzer)
+
+			return NewTracer(scanner, newAnalyzer(), applier, verifier, paths).Trace(cmd.Context())
+		},
+	}
+
+	sf.register(cmd)
+
+	return cmd
+}
+
+func newInstallHookCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:           "install-hook [flags] [<paths>...]",
+		Short:         "Install the git pre-commit hook that runs reqmd hook pre-commit <paths>",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if len(paths) == 0 {
+				paths = defaultHookPaths
+			}
+			hookPath, err := InstallHook(".", paths, force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("reqmd: %s hook is installed: %s\n", preCommitHookName, hookPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the existing hook")
+
+	return cmd
+}
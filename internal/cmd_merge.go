@@ -0,0 +1,98 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newMergeDriverCmd() *cobra.Command {
+	var mcfg MergeConfig
+
+	cmd := &cobra.Command{
+		Use:           "merge-driver [flags] <base> <current> <other> [<path>]",
+		Short:         "Git merge driver that regenerates coverage footnotes, the result is written to <current>",
+		Args:          cobra.RangeArgs(3, 4),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			currentPath := args[1]
+			path := currentPath
+			if len(args) > 3 {
+				path = args[3]
+			}
+
+			var contents [3][]byte
+			for i, p := range args[:3] {
+				content, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				contents[i] = content
+			}
+
+			merged, conflicts, err := MergeMarkdown(&mcfg, path, contents[0], contents[1], contents[2])
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(currentPath, merged, 0644); err != nil {
+				return err
+			}
+			if conflicts > 0 {
+				return fmt.Errorf("reqmd: %d merge conflict(s) in %s", conflicts, path)
+			}
+			Verbose("Merged", "path", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&mcfg.ConflictMarkerSize, "marker-size", defaultConflictMarkerSize, "Length of conflict markers, pass %L")
+
+	return cmd
+}
+
+func newResolveCmd() *cobra.Command {
+	var sf scanFlags
+
+	cmd := &cobra.Command{
+		Use:           "resolve [flags] <paths>...",
+		Short:         "Resolve merge conflicts in coverage annotations of markdown files and trace requirements",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+
+			scfg, acfg, err := sf.configs(paths)
+			if err != nil {
+				return err
+			}
+
+			files, err := ResolveConflictedFiles(paths)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				fmt.Printf("reqmd: resolved %s\n", file)
+			}
+
+			// Coverers of the resolved files are regenerated from a fresh scan
+			newAnalyzer := func() IAnalyzer { return NewAnalyzerEx(acfg) }
+			scanner := NewScanner(scfg)
+			applier := NewApplier(&ApplierConfig{})
+			verifier := NewVerifier(scfg, newAnalyzer)
+
+			return NewTracer(scanner, newAnalyzer(), applier, verifier, paths).Trace(cmd.Context())
+		},
+	}
+
+	sf.register(cmd)
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecRootCmd_Context(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "req.md"), []byte("---\nreqmd.package: pkg\n---\n\n- `~REQ001~`\n"), 0644))
+
+	t.Run("timeout", func(t *testing.T) {
+		err := ExecRootCmd([]string{"reqmd", "trace", "--no-vcs", "--timeout", "1ns", dir}, "0.0.1")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "reqmd: --timeout 1ns exceeded")
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := ExecRootCmdEx(ctx, []string{"reqmd", "trace", "--no-vcs", dir}, "0.0.1")
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorContains(t, err, "reqmd: interrupted")
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		require.NoError(t, ExecRootCmd([]string{"reqmd", "trace", "--no-vcs", "--fail-fast", "--timeout", "1m", dir}, "0.0.1"))
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Configuration file that is looked up in the current folder if --config is not specified
+const defaultConfigFileName = ".reqmd.json"
+
+// Config is the content of the configuration file, ref. docs/op-url-templates.md
+type Config struct {
+	// Maps hosts to URL templates or to names of the built-in providers
+	URLTemplates map[string]string `json:"urlTemplates"`
+	// Ids of new footnotes: "numeric" (default) or "name"
+	FootnoteIds FootnoteIdStrategy `json:"footnoteIds"`
+	// Overrides default severities of ProcessingError codes, e.g. {"statusemoji": "warning"}
+	Severities Severities `json:"severities"`
+}
+
+// LoadConfig reads the configuration file.
+// If path is empty, defaultConfigFileName is used and it is not an error if it does not exist.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFileName
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	Verbose("Config loaded", "path", path)
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	templates := make(map[string]string, len(c.URLTemplates))
+	for host, tmpl := range c.URLTemplates {
+		if strings.Contains(tmpl, "{") {
+			if err := validateURLTemplate(tmpl); err != nil {
+				return err
+			}
+		} else if _, ok := findGitProviderByName(tmpl); !ok {
+			return fmt.Errorf("unknown git provider for %s: %s", host, tmpl)
+		}
+		templates[strings.ToLower(host)] = tmpl
+	}
+	c.URLTemplates = templates
+
+	ids, err := ParseFootnoteIdStrategy(string(c.FootnoteIds))
+	if err != nil {
+		return err
+	}
+	c.FootnoteIds = ids
+
+	return c.Severities.validate()
+}
+
+// FootnoteIdStrategy returns the strategy given by the flag, the strategy of the file is used if the flag is empty
+func (c *Config) FootnoteIdStrategy(flag string) (FootnoteIdStrategy, error) {
+	if flag == "" {
+		return c.FootnoteIds, nil
+	}
+	return ParseFootnoteIdStrategy(flag)
+}
+
+// GitConfig returns the git configuration that is defined by the file
+func (c *Config) GitConfig() *GitConfig {
+	return &GitConfig{URLTemplates: c.URLTemplates}
+}
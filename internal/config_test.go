@@ -0,0 +1,89 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("url templates", func(t *testing.T) {
+		path := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{
+			"urlTemplates": {
+				"Git.Example.com": "forgejo",
+				"code.example.com": "{base}/browse/{path}?at={ref}#{line}"
+			}
+		}`), 0644))
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"git.example.com":  "forgejo",
+			"code.example.com": "{base}/browse/{path}?at={ref}#{line}",
+		}, cfg.GitConfig().URLTemplates)
+	})
+
+	t.Run("footnote ids", func(t *testing.T) {
+		path := filepath.Join(dir, "footnotes.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"footnoteIds": "name"}`), 0644))
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+
+		ids, err := cfg.FootnoteIdStrategy("")
+		require.NoError(t, err)
+		require.Equal(t, FootnoteIdsName, ids)
+
+		// The flag takes precedence
+		ids, err = cfg.FootnoteIdStrategy("numeric")
+		require.NoError(t, err)
+		require.Equal(t, FootnoteIdsNumeric, ids)
+
+		_, err = cfg.FootnoteIdStrategy("random")
+		require.ErrorContains(t, err, "unknown footnote id strategy")
+
+		// Numeric by default
+		cfg, err = LoadConfig(filepath.Join(dir, "config.json"))
+		require.NoError(t, err)
+		require.Equal(t, FootnoteIdsNumeric, cfg.FootnoteIds)
+	})
+
+	t.Run("severities", func(t *testing.T) {
+		path := filepath.Join(dir, "severities.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"severities": {"statusemoji": "warning", "multisites": "info"}}`), 0644))
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		require.Equal(t, SeverityWarning, cfg.Severities.Of("statusemoji"))
+		require.Equal(t, SeverityInfo, cfg.Severities.Of("multisites"))
+		require.Equal(t, SeverityError, cfg.Severities.Of("dupreqid"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"urlTemplates": {"git.example.com": "svn"}}`), 0644))
+		_, err := LoadConfig(path)
+		require.ErrorContains(t, err, "unknown git provider")
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"footnoteIds": "random"}`), 0644))
+		_, err = LoadConfig(path)
+		require.ErrorContains(t, err, "unknown footnote id strategy")
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"severities": {"nosuchcode": "warning"}}`), 0644))
+		_, err = LoadConfig(path)
+		require.ErrorContains(t, err, "unknown error code in severities: nosuchcode")
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"severities": {"statusemoji": "fatal"}}`), 0644))
+		_, err = LoadConfig(path)
+		require.ErrorContains(t, err, "unknown severity: fatal")
+
+		_, err = LoadConfig(filepath.Join(dir, "missing.json"))
+		require.Error(t, err)
+	})
+}
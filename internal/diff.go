@@ -0,0 +1,190 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	diffContextLines = 3
+	noNewlineMarker  = `\ No newline at end of file`
+	noNewlineSuffix  = "\x00" // marks the last line of the file that is not terminated by "\n"
+)
+
+type diffOp int
+
+const (
+	diffOpEqual diffOp = iota
+	diffOpDelete
+	diffOpInsert
+)
+
+// diffLine is a single line of a line-based diff
+type diffLine struct {
+	op   diffOp
+	text string // line content without the trailing "\n"
+}
+
+// diffLines computes the line-based difference between a and b
+func diffLines(a, b []string) []diffLine {
+	var res []diffLine
+	for _, d := range gitdiff.Do(joinDiffText(a), joinDiffText(b)) {
+		op := diffOpEqual
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = diffOpDelete
+		case diffmatchpatch.DiffInsert:
+			op = diffOpInsert
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			res = append(res, diffLine{op: op, text: strings.TrimSuffix(line, "\n")})
+		}
+	}
+	return res
+}
+
+// joinDiffText terminates every line with "\n" so that the last line is compared like the others
+func joinDiffText(lines []string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// splitDiffText splits content into lines and reports whether the last line is terminated by "\n"
+func splitDiffText(content []byte) (lines []string, eol bool) {
+	if len(content) == 0 {
+		return nil, true
+	}
+	lines = strings.Split(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1], true
+	}
+	return lines, false
+}
+
+// UnifiedDiff returns the difference between the original and the updated content of the file
+// in the unified format accepted by `git apply`. Empty string is returned if there is no difference.
+// path is expected to be slashed and relative to the folder where the patch is applied.
+func UnifiedDiff(path string, original, updated []byte) string {
+	oldLines, oldEOL := splitDiffText(original)
+	newLines, newEOL := splitDiffText(updated)
+
+	// The last line without "\n" differs from the same line with it
+	if !oldEOL {
+		oldLines[len(oldLines)-1] += noNewlineSuffix
+	}
+	if !newEOL {
+		newLines[len(newLines)-1] += noNewlineSuffix
+	}
+
+	lines := diffLines(oldLines, newLines)
+
+	var sb strings.Builder
+	for _, h := range buildDiffHunks(lines) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+		}
+		h.write(&sb, lines)
+	}
+	return sb.String()
+}
+
+// diffHunk is a range of diff lines [from, to) and the positions of the range in the old and new files
+type diffHunk struct {
+	from, to           int
+	oldStart, newStart int // 0-based line indexes
+	oldCount, newCount int
+}
+
+// buildDiffHunks groups changed lines with diffContextLines of context, close changes are merged into one hunk
+func buildDiffHunks(lines []diffLine) []diffHunk {
+	var hunks []diffHunk
+	oldIdx, newIdx := 0, 0
+	var cur *diffHunk
+	lastChange := -1
+
+	for i, l := range lines {
+		if l.op != diffOpEqual {
+			if cur == nil || i-lastChange-1 > 2*diffContextLines {
+				if cur != nil {
+					hunks = append(hunks, cur.close(lines, lastChange))
+				}
+				from := max(i-diffContextLines, 0)
+				if len(hunks) > 0 {
+					from = max(from, hunks[len(hunks)-1].to)
+				}
+				// Lines between from and i are context lines
+				cur = &diffHunk{from: from, oldStart: oldIdx - (i - from), newStart: newIdx - (i - from)}
+			}
+			lastChange = i
+		}
+		switch l.op {
+		case diffOpEqual:
+			oldIdx++
+			newIdx++
+		case diffOpDelete:
+			oldIdx++
+		case diffOpInsert:
+			newIdx++
+		}
+	}
+	if cur != nil {
+		hunks = append(hunks, cur.close(lines, lastChange))
+	}
+	return hunks
+}
+
+// close sets the end of the hunk after the last change plus context and counts lines
+func (h diffHunk) close(lines []diffLine, lastChange int) diffHunk {
+	h.to = min(lastChange+1+diffContextLines, len(lines))
+	for _, l := range lines[h.from:h.to] {
+		if l.op != diffOpInsert {
+			h.oldCount++
+		}
+		if l.op != diffOpDelete {
+			h.newCount++
+		}
+	}
+	return h
+}
+
+func (h diffHunk) write(sb *strings.Builder, lines []diffLine) {
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+	for _, l := range lines[h.from:h.to] {
+		prefix := " "
+		switch l.op {
+		case diffOpDelete:
+			prefix = "-"
+		case diffOpInsert:
+			prefix = "+"
+		}
+		text, noEOL := strings.CutSuffix(l.text, noNewlineSuffix)
+		sb.WriteString(prefix + text + "\n")
+		if noEOL {
+			sb.WriteString(noNewlineMarker + "\n")
+		}
+	}
+}
+
+// hunkRange formats a range of lines, empty ranges refer to the line before the range
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		updated  string
+		want     string
+	}{
+		{
+			name:     "no changes",
+			original: "a\nb\n",
+			updated:  "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "replace line",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			updated:  "1\n2\n3\n4x\n5\n6\n7\n8\n",
+			want: "diff --git a/req.md b/req.md\n--- a/req.md\n+++ b/req.md\n" +
+				"@@ -1,7 +1,7 @@\n 1\n 2\n 3\n-4\n+4x\n 5\n 6\n 7\n",
+		},
+		{
+			name:     "append lines to file without newline",
+			original: "1\n2",
+			updated:  "1\n2\n\n[^1]: note\n",
+			want: "diff --git a/req.md b/req.md\n--- a/req.md\n+++ b/req.md\n" +
+				"@@ -1,2 +1,4 @@\n 1\n-2\n\\ No newline at end of file\n+2\n+\n+[^1]: note\n",
+		},
+		{
+			name:     "insert into empty file",
+			original: "",
+			updated:  "1\n",
+			want:     "diff --git a/req.md b/req.md\n--- a/req.md\n+++ b/req.md\n@@ -0,0 +1 @@\n+1\n",
+		},
+		{
+			name:     "two hunks",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			updated:  "1x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12x\n",
+			want: "diff --git a/req.md b/req.md\n--- a/req.md\n+++ b/req.md\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+1x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+12x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnifiedDiff("req.md", []byte(tt.original), []byte(tt.updated)))
+		})
+	}
+}
+
+// Patches shall be accepted by git apply
+func TestUnifiedDiff_GitApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	var lines []string
+	for i := range 40 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	original := strings.Join(lines, "\r\n")
+	lines[3] = "`~REQ001~`uncvrd[^1]❓"
+	lines[20] = ""
+	lines = append(lines[:30], lines[32:]...)
+	lines = append(lines, "", "[^1]: `[~pkg1/REQ001~impl]`", "")
+	updated := strings.Join(lines, "\r\n")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "req.md"), []byte(original), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "req.patch"), []byte(UnifiedDiff("req.md", []byte(original), []byte(updated))), 0644))
+
+	cmd := exec.Command("git", "apply", "req.patch")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	content, err := os.ReadFile(filepath.Join(dir, "req.md"))
+	require.NoError(t, err)
+	require.Equal(t, updated, string(content))
+}
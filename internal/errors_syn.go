@@ -74,3 +74,33 @@ func NewErrReqType(filePath string, line int, typeIdentifiers []string, reqName
 		Message:  fmt.Sprintf("requirement type must be one of %v: %v", typeIdentifiers, reqName),
 	}
 }
+
+// CoverageStatusEmoji shall match CoverageStatusWord
+func NewErrStatusEmoji(filePath string, line int, word CoverageStatusWord, emoji CoverageStatusEmoji) ProcessingError {
+	return ProcessingError{
+		Code:     "statusemoji",
+		FilePath: filePath,
+		Line:     line,
+		Message:  fmt.Sprintf("CoverageStatusEmoji shall be %s for '%s': %s", CoverageStatusEmojiOf(word), word, emoji),
+	}
+}
+
+// Codes of `reqmd:ignore` comments shall be ProcessingError codes, otherwise a typo suppresses nothing
+func NewErrIgnoreCode(filePath string, line int, code string) ProcessingError {
+	return ProcessingError{
+		Code:     "ignorecode",
+		FilePath: filePath,
+		Line:     line,
+		Message:  "unknown error code in reqmd:ignore: " + code + ", ref. reqmd explain",
+	}
+}
+
+// Conflict markers shall be resolved, ref. `reqmd resolve`
+func NewErrMergeConflict(filePath string, line int) ProcessingError {
+	return ProcessingError{
+		Code:     "mergeconflict",
+		FilePath: filePath,
+		Line:     line,
+		Message:  "unresolved merge conflict, resolve it manually or using reqmd resolve if only annotations conflict",
+	}
+}
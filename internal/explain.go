@@ -0,0 +1,50 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+// Descriptions, examples and fixes of ProcessingError codes, one file per code, ref. `reqmd explain`
+//
+//go:embed errcodes/*.md
+var errCodeDocs embed.FS
+
+// ExplainCode returns the description, the example and the fix of the ProcessingError code
+func ExplainCode(code string) (string, error) {
+	if _, ok := defaultSeverities[code]; !ok {
+		return "", fmt.Errorf("unknown error code: %s, run reqmd explain to list codes", code)
+	}
+	data, err := errCodeDocs.ReadFile("errcodes/" + code + ".md")
+	if err != nil {
+		return "", fmt.Errorf("no explanation for error code %s: %w", code, err)
+	}
+	return string(data), nil
+}
+
+// ExplainCodes returns codes with their titles, one per line
+func ExplainCodes() (string, error) {
+	var sb strings.Builder
+	for _, code := range ProcessingErrorCodes() {
+		doc, err := ExplainCode(code)
+		if err != nil {
+			return "", err
+		}
+		title, _, _ := strings.Cut(doc, "\n")
+		sb.WriteString(strings.TrimPrefix(title, "# "))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// explainHint returns the hint that is appended to error lines, empty if the code is not explained
+func explainHint(code string) string {
+	if _, ok := defaultSeverities[code]; !ok {
+		return ""
+	}
+	return " (run reqmd explain " + code + ")"
+}
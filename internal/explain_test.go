@@ -0,0 +1,62 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Every code constructed in errors_syn.go and errors_sem.go has a default severity and an explanation, and vice versa
+func TestExplainCode_Catalogue(t *testing.T) {
+	codeRegex := regexp.MustCompile(`Code:\s*"([a-z]+)"`)
+	var codes []string
+	for _, file := range []string{"errors_syn.go", "errors_sem.go"} {
+		src, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, m := range codeRegex.FindAllStringSubmatch(string(src), -1) {
+			codes = append(codes, m[1])
+		}
+	}
+	require.ElementsMatch(t, codes, ProcessingErrorCodes())
+
+	docs, err := fs.Glob(errCodeDocs, "errcodes/*.md")
+	require.NoError(t, err)
+	require.Len(t, docs, len(codes))
+
+	for _, code := range codes {
+		doc, err := ExplainCode(code)
+		require.NoError(t, err, code)
+		require.True(t, strings.HasPrefix(doc, "# "+code+": "), code)
+		require.Contains(t, doc, "Example", code)
+		require.Contains(t, doc, "Fix: ", code)
+	}
+}
+
+func TestExplainCode(t *testing.T) {
+	_, err := ExplainCode("nosuchcode")
+	require.ErrorContains(t, err, "unknown error code: nosuchcode")
+
+	_, err = ExplainCode("../explain")
+	require.Error(t, err)
+
+	list, err := ExplainCodes()
+	require.NoError(t, err)
+	require.Contains(t, list, "covstatus: CoverageStatusWord is invalid\n")
+	require.Len(t, strings.Split(strings.TrimSpace(list), "\n"), len(ProcessingErrorCodes()))
+}
+
+func TestProcessingErrors_ExplainHint(t *testing.T) {
+	err := &ProcessingErrors{Errors: []ProcessingError{
+		NewErrReqIdent("req.md", 5),
+		{FilePath: "req.md", Message: "Error reading file: EOF"},
+	}}
+	require.Equal(t, "req.md:5: reqident: RequirementName shall be an identifier (run reqmd explain reqident)\n"+
+		"req.md:0: : Error reading file: EOF", err.Error())
+}
@@ -6,6 +6,7 @@ package internal
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -24,6 +25,11 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 	}
 	defer file.Close()
 
+	return parseFileEx(pctx, filePath, file)
+}
+
+// parseFileEx parses the content of the file that is read from r, e.g. from the git index
+func parseFileEx(pctx *ScannerContext, filePath string, r io.Reader) (*FileStructure, []ProcessingError, error) {
 	var errors []ProcessingError
 
 	// Determine file type based on extension
@@ -39,24 +45,50 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 	}
 
 	// Parse file contents
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
 	inHeader := false
 	inCodeBlock := false
 	var lastFenceLine int
+	var summary *SummaryBlock // currently open SummaryBlock
 
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
+		if strings.TrimSpace(line) != "" {
+			structure.LastLine = lineNum
+		}
 
 		// Check if the line should be ignored based on ignore patterns
 		if shouldIgnoreLine(pctx, line) {
 			if IsVerbose {
 				Verbose("parseFile: ignoring line", "line", lineNum, "file", filePath)
 			}
+			// Sites of ignored lines are not parsed, but their coverage footnotes shall be kept
+			if fileType == FileTypeMarkdown && !inCodeBlock {
+				for _, match := range RequirementSiteRegex.FindAllStringSubmatch(line, -1) {
+					if match[3] == "" {
+						continue
+					}
+					if structure.IgnoredFootnoteRefs == nil {
+						structure.IgnoredFootnoteRefs = make(map[CoverageFootnoteId]bool)
+					}
+					structure.IgnoredFootnoteRefs[CoverageFootnoteId(match[3])] = true
+				}
+			}
 			continue
 		}
 
+		// Suppression comments are not processed inside markdown code blocks
+		if fileType == FileTypeMarkdown && !inCodeBlock {
+			if suppression := parseSuppression(line, lineNum); suppression != nil {
+				structure.Suppressions = append(structure.Suppressions, *suppression)
+				for _, code := range suppression.unknownCodes() {
+					errors = append(errors, NewErrIgnoreCode(filePath, lineNum, code))
+				}
+			}
+		}
+
 		// Source file parsing - always parse coverage tags regardless of file type
 		if !inCodeBlock {
 			tags := parseCoverageTags(filePath, line, lineNum)
@@ -67,6 +99,13 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 
 		// Markdown specific parsing - only if file is markdown
 		if fileType == FileTypeMarkdown {
+			// Conflict markers break RequirementSites and footnotes, ref. ResolveConflicts().
+			// Markers in code blocks are examples
+			if !inCodeBlock && isConflictMarker(line, conflictMarkerOurs) {
+				errors = append(errors, NewErrMergeConflict(filePath, lineNum))
+				continue
+			}
+
 			// Check for code block markers
 			if isCodeBlockMarker(line) {
 				if !inCodeBlock {
@@ -107,6 +146,25 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 
 			// Only parse requirements and footnotes when not in a code block
 			if !inCodeBlock {
+				// Track summary blocks
+				if scope, ok := parseSummaryBeginMarker(line); ok {
+					if summary != nil {
+						// Previous block has no end marker
+						structure.SummaryBlocks = append(structure.SummaryBlocks, unterminatedSummaryBlock(summary))
+					}
+					summary = &SummaryBlock{Line: lineNum, EndLine: lineNum, Scope: scope}
+					continue
+				}
+				if summary != nil {
+					if SummaryEndMarkerRegex.MatchString(line) {
+						summary.EndLine = lineNum
+						structure.SummaryBlocks = append(structure.SummaryBlocks, *summary)
+						summary = nil
+						continue
+					}
+					summary.Content = append(summary.Content, line)
+				}
+
 				// Parse requirements
 				requirements := parseRequirementsEx(pctx, filePath, line, lineNum, &errors)
 				structure.Requirements = append(structure.Requirements, requirements...)
@@ -120,6 +178,10 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 		}
 	}
 
+	if summary != nil {
+		structure.SummaryBlocks = append(structure.SummaryBlocks, unterminatedSummaryBlock(summary))
+	}
+
 	// Markdown checks for unmatched fence at end of file
 	if fileType == FileTypeMarkdown && inCodeBlock {
 		errors = append(errors, NewErrUnmatchedFence(filePath, lastFenceLine))
@@ -132,6 +194,10 @@ func parseFile(pctx *ScannerContext, filePath string) (*FileStructure, []Process
 		})
 	}
 
+	if len(structure.Suppressions) > 0 {
+		errors, structure.SuppressedErrors = suppressErrors(map[string][]Suppression{filePath: structure.Suppressions}, errors)
+	}
+
 	return structure, errors, nil
 }
 
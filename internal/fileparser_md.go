@@ -19,6 +19,8 @@ type ScannerContext struct {
 	// IgnorePatterns contains compiled regular expressions that match lines to be ignored
 	IgnorePatterns []*regexp.Regexp
 	TypeRegistry   *TypeRegistry
+	// If true, mismatched CoverageStatusEmojis are not reported, ref. ScannerConfig.RepairStatusEmoji
+	RepairStatusEmoji bool
 }
 
 // isCodeBlockMarker checks if a line is a code block marker, handling indentation
@@ -76,13 +78,17 @@ func parseRequirementsEx(sctx *ScannerContext, filePath string, line string, lin
 			}
 		}
 
-		// TODO syntax error to match CoverageStatusEmoji and CoverageStatusWord
-
 		if req.HasAnnotationRef && covStatus == "" {
 			*errors = append(*errors, NewErrCoverageStatusWord(filePath, lineNum, covStatus))
 			return requirements
 		}
 
+		// Missing CoverageStatusEmoji is allowed by the syntax, it is added by the analyzer
+		if req.HasAnnotationRef && !sctx.RepairStatusEmoji && req.CoverageStatusEmoji != CoverageStatusEmojiEmpty &&
+			req.CoverageStatusEmoji != CoverageStatusEmojiOf(req.CoverageStatusWord) {
+			*errors = append(*errors, NewErrStatusEmoji(filePath, lineNum, req.CoverageStatusWord, req.CoverageStatusEmoji))
+		}
+
 		requirements = append(requirements, req)
 	}
 
@@ -96,6 +102,7 @@ func ParseCoverageFootnote(mctx *ScannerContext, filePath string, line string, l
 		footnote = &CoverageFootnote{
 			CoverageFootnoteId: CoverageFootnoteId(matches[1]),
 			PackageId:          PackageId(matches[2]),
+			RequirementName:    RequirementName(matches[3]),
 			Line:               lineNum,
 		}
 
@@ -123,3 +130,24 @@ func ParseCoverageFootnote(mctx *ScannerContext, filePath string, line string, l
 	}
 	return footnote
 }
+
+// parseSummaryBeginMarker returns the scope of the SummaryBlock if the line is a SummaryBeginMarker
+func parseSummaryBeginMarker(line string) (SummaryScope, bool) {
+	matches := SummaryBeginMarkerRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	if matches[1] == string(SummaryScopePackage) {
+		return SummaryScopePackage, true
+	}
+	return SummaryScopeFile, true
+}
+
+// unterminatedSummaryBlock converts a block without the end marker into a block that consists of the begin marker only
+func unterminatedSummaryBlock(block *SummaryBlock) SummaryBlock {
+	return SummaryBlock{
+		Line:    block.Line,
+		EndLine: block.Line,
+		Scope:   block.Scope,
+	}
+}
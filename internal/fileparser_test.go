@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -63,6 +64,29 @@ func TestFileParser_md_error_pkgident(t *testing.T) {
 	require.Len(t, errors, 1, "expected exactly 1 syntax error")
 }
 
+// Conflict markers in code blocks are examples, not merge conflicts
+func TestFileParser_md_FencedConflictMarkers(t *testing.T) {
+	content := "---\nreqmd.package: pkg\n---\n\n" +
+		"```markdown\n" +
+		"<<<<<<< HEAD\n" +
+		"Ours\n" +
+		"=======\n" +
+		"Theirs\n" +
+		">>>>>>> feature\n" +
+		"```\n" +
+		"- `~REQ001~`\n"
+
+	structure, errs, err := parseFileEx(newMdCtx(), "req.md", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	require.Len(t, structure.Requirements, 1)
+
+	_, errs, err = parseFileEx(newMdCtx(), "req.md", strings.NewReader(strings.NewReplacer("```markdown\n", "", "```\n", "").Replace(content)))
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	require.Equal(t, "mergeconflict", errs[0].Code)
+}
+
 func TestFileParser_md_IgnorePackage(t *testing.T) {
 	// Create a temporary file for testing
 	content := []byte(`---
@@ -280,6 +304,24 @@ func TestParseRequirements_table(t *testing.T) {
 	}
 }
 
+func TestParseRequirements_StatusEmoji(t *testing.T) {
+	line := "`~Post.handler~`covrd[^1]❓"
+
+	var errors []ProcessingError
+	got := parseRequirementsEx(&ScannerContext{}, "test.md", line, 1, &errors)
+	require.Len(t, got, 1)
+	require.Len(t, errors, 1)
+	require.Equal(t, "statusemoji", errors[0].Code)
+	require.Equal(t, "CoverageStatusEmoji shall be ✅ for 'covrd': ❓", errors[0].Message)
+
+	// The analyzer repairs the emoji
+	errors = nil
+	got = parseRequirementsEx(&ScannerContext{RepairStatusEmoji: true}, "test.md", line, 1, &errors)
+	require.Len(t, got, 1)
+	require.Empty(t, errors)
+	require.Equal(t, CoverageStatusEmojiUncvrd, got[0].CoverageStatusEmoji)
+}
+
 func TestParseCoverageFootnote(t *testing.T) {
 	line := "[^~REQ002~]: `[~com.example.basic/REQ002~impl]`[folder1/filename1:line1:impl](https://example.com/pkg1/filename1#L11), [folder2/filename2:line2:test](https://example.com/pkg2/filename2#L22)"
 	ctx := &ScannerContext{}
@@ -403,3 +445,39 @@ func TestParseRequirements_errors(t *testing.T) {
 		})
 	}
 }
+
+func TestFileParser_SummaryBlocks(t *testing.T) {
+	content := []byte(`---
+reqmd.package: com.example
+---
+<!-- reqmd:summary -->
+| Type | Covered | Uncovered | Total |
+<!-- /reqmd:summary -->
+` + "```" + `
+<!-- reqmd:summary -->
+` + "```" + `
+<!-- reqmd:summary package -->
+` + "`~REQ001~`" + `
+`)
+	tmpfile := filepath.Join(t.TempDir(), "test.md")
+	require.NoError(t, os.WriteFile(tmpfile, content, 0644))
+
+	structure, errs, err := parseFile(newMdCtx(), tmpfile)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, structure.Requirements, 1)
+
+	// The marker inside the code block is ignored, the last block has no end marker
+	require.Len(t, structure.SummaryBlocks, 2)
+	assert.Equal(t, SummaryBlock{
+		Line:    4,
+		EndLine: 6,
+		Scope:   SummaryScopeFile,
+		Content: []string{"| Type | Covered | Uncovered | Total |"},
+	}, structure.SummaryBlocks[0])
+	assert.Equal(t, SummaryBlock{
+		Line:    10,
+		EndLine: 10,
+		Scope:   SummaryScopePackage,
+	}, structure.SummaryBlocks[1])
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fix is a fix of a ProcessingError found by FixContent()
+type Fix struct {
+	Code    string // Code of the ProcessingError
+	Line    int
+	Message string // What is fixed or, if not Safe, what shall be done
+	Safe    bool   // Safe fixes are applied, other fixes are suggestions
+}
+
+func (f *Fix) String() string {
+	if f.Safe {
+		return fmt.Sprintf("%d: %s: %s", f.Line, f.Code, f.Message)
+	}
+	return fmt.Sprintf("%d: %s: suggestion: %s", f.Line, f.Code, f.Message)
+}
+
+// Characters that are not allowed in identifiers, ref. identifierRegex
+var nonIdentifierCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_.]+`)
+
+// FixContent applies safe fixes of syntax and semantic errors to the markdown content.
+// Safe fixes do not change RequirementIds and the prose:
+//   - covstatus: invalid or missing CoverageStatusWord is replaced according to CoverageStatusEmoji, `reqmd trace` updates it anyway
+//   - covstatus: CoverageStatusWord `covered` is migrated to `covrd`
+//   - statusemoji: CoverageStatusEmoji is replaced according to CoverageStatusWord
+//   - pkgmismatch: package of the CoverageFootnote is replaced by the PackageId of the header
+//
+// Other fixes are suggestions:
+//   - reqident: RequirementName can be converted to an identifier, CoverageTags shall be renamed as well
+//   - multisites: the line can be split so that every RequirementSite is on its own line
+//
+// fixed is the same as content if there are no safe fixes.
+func FixContent(sctx *ScannerContext, path string, content []byte) (fixed []byte, fixes []Fix, err error) {
+	// Mismatched CoverageStatusEmojis shall be reported
+	ctx := *sctx
+	ctx.RepairStatusEmoji = false
+	structure, errs, err := parseFileEx(&ctx, path, bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines, hasCRLF := splitLinesPreserveEndings(content)
+
+	for _, e := range errs {
+		// E.g. errors of reading the file, it can not be fixed
+		if e.Line < 1 || e.Line > len(lines) {
+			return nil, nil, fmt.Errorf("%s: %s", path, e.Message)
+		}
+		line := lines[e.Line-1]
+		switch e.Code {
+		case "covstatus":
+			if fixedLine, word, ok := fixCoverageStatusWord(line); ok {
+				lines[e.Line-1] = fixedLine
+				fixes = append(fixes, Fix{Code: e.Code, Line: e.Line, Safe: true,
+					Message: fmt.Sprintf("CoverageStatusWord is set to '%s'", word)})
+			}
+		case "statusemoji":
+			m := RequirementSiteRegex.FindStringSubmatchIndex(line)
+			if m == nil || m[4] < 0 || m[8] < 0 {
+				continue
+			}
+			emoji := CoverageStatusEmojiOf(CoverageStatusWord(line[m[4]:m[5]]))
+			lines[e.Line-1] = line[:m[8]] + string(emoji) + line[m[9]:]
+			fixes = append(fixes, Fix{Code: e.Code, Line: e.Line, Safe: true,
+				Message: fmt.Sprintf("CoverageStatusEmoji is set to %s", emoji)})
+		case "reqident":
+			m := RequirementSiteRegex.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if ident := toIdentifier(m[1]); ident != "" {
+				fixes = append(fixes, Fix{Code: e.Code, Line: e.Line,
+					Message: fmt.Sprintf("rename '%s' to '%s' here and in CoverageTags", m[1], ident)})
+			}
+		case "multisites":
+			sites := RequirementSiteRegex.FindAllString(line, -1)
+			fixes = append(fixes, Fix{Code: e.Code, Line: e.Line,
+				Message: "split the line so that every RequirementSite is on its own line: " + strings.Join(sites, ", ")})
+		}
+	}
+
+	if structure.PackageId != "" {
+		for _, site := range structure.Requirements {
+			if site.CoverageStatusWord != CoverageStatusWordCovered {
+				continue
+			}
+			line := lines[site.Line-1]
+			m := RequirementSiteRegex.FindStringSubmatchIndex(line)
+			lines[site.Line-1] = line[:m[4]] + string(CoverageStatusWordCovrd) + line[m[5]:]
+			fixes = append(fixes, Fix{Code: "covstatus", Line: site.Line, Safe: true,
+				Message: fmt.Sprintf("CoverageStatusWord '%s' is migrated to '%s'", CoverageStatusWordCovered, CoverageStatusWordCovrd)})
+		}
+		for _, f := range structure.CoverageFootnotes {
+			if f.PackageId == "" || f.PackageId == structure.PackageId {
+				continue
+			}
+			lines[f.Line-1] = strings.Replace(lines[f.Line-1], "`[~"+string(f.PackageId)+"/", "`[~"+string(structure.PackageId)+"/", 1)
+			fixes = append(fixes, Fix{Code: "pkgmismatch", Line: f.Line, Safe: true,
+				Message: fmt.Sprintf("CoverageFootnote package is set to '%s'", structure.PackageId)})
+		}
+	}
+
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].Line < fixes[j].Line
+	})
+	return joinLinesPreserveEndings(lines, hasCRLF), fixes, nil
+}
+
+// fixCoverageStatusWord replaces the invalid or missing CoverageStatusWord of the RequirementSite in the line.
+// The word is `covrd` if CoverageStatusEmoji is ✅ and `uncvrd` otherwise.
+func fixCoverageStatusWord(line string) (fixed string, word CoverageStatusWord, ok bool) {
+	m := RequirementSiteRegex.FindStringSubmatchIndex(line)
+	if m == nil || m[6] < 0 {
+		return line, "", false
+	}
+	word = CoverageStatusWordUncvrd
+	if m[8] >= 0 && CoverageStatusEmoji(line[m[8]:m[9]]) == CoverageStatusEmojiCovered {
+		word = CoverageStatusWordCovrd
+	}
+	if m[4] < 0 {
+		// Missing word goes right after the RequirementSiteLabel
+		return line[:m[3]+2] + string(word) + line[m[3]+2:], word, true
+	}
+	return line[:m[4]] + string(word) + line[m[5]:], word, true
+}
+
+// toIdentifier converts the name to an identifier, e.g. "Post-handler" to "Post_handler".
+// Returns empty string if the name can not be converted.
+func toIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		part = strings.Trim(nonIdentifierCharsRegex.ReplaceAllString(part, "_"), "_")
+		if part == "" {
+			return ""
+		}
+		if !(part[0] >= 'a' && part[0] <= 'z' || part[0] >= 'A' && part[0] <= 'Z') {
+			part = "R" + part
+		}
+		parts[i] = part
+	}
+	res := strings.Join(parts, ".")
+	if !identifierRegex.MatchString(res) {
+		return ""
+	}
+	return res
+}
+
+// FixConfig configures FixFiles
+type FixConfig struct {
+	IgnorePatterns []*regexp.Regexp
+	DryRun         bool // make no changes to files
+	Diff           bool // print changes as a unified diff instead of the list of fixes
+}
+
+// FixFiles fixes markdown files under paths, ref. FixContent().
+// Files are written only if all of them are fixed. Fixes are printed to w, with Diff suggestions are printed to wErr.
+// Returns the number of suggestions.
+func FixFiles(fcfg *FixConfig, paths []string, w, wErr io.Writer) (suggestions int, err error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	sctx := &ScannerContext{IgnorePatterns: fcfg.IgnorePatterns}
+
+	var changes []*mdFileChange
+	err = walkMdFiles(paths, func(path string) error {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+		fixed, fixes, err := FixContent(sctx, absPath, content)
+		if err != nil {
+			return err
+		}
+
+		relPath := absPath
+		if rel, err := filepath.Rel(wd, absPath); err == nil {
+			relPath = filepath.ToSlash(rel)
+		}
+		for _, fix := range fixes {
+			if !fix.Safe {
+				suggestions++
+			}
+			if fcfg.Diff {
+				if !fix.Safe {
+					fmt.Fprintf(wErr, "%s:%s\n", relPath, fix.String())
+				}
+				continue
+			}
+			fmt.Fprintf(w, "%s:%s\n", relPath, fix.String())
+		}
+
+		if !bytes.Equal(fixed, content) {
+			changes = append(changes, &mdFileChange{
+				path:     absPath,
+				mode:     info.Mode().Perm(),
+				original: content,
+				updated:  fixed,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if fcfg.Diff {
+		for _, change := range changes {
+			relPath, err := filepath.Rel(wd, change.path)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get relative path for %s: %w", change.path, err)
+			}
+			fmt.Fprint(w, UnifiedDiff(filepath.ToSlash(relPath), change.original, change.updated))
+		}
+	}
+
+	if fcfg.DryRun || len(changes) == 0 {
+		return suggestions, nil
+	}
+	return suggestions, commitMdFileChanges(changes)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixContent(t *testing.T) {
+	t.Run("safe fixes", func(t *testing.T) {
+		content := mdHeader +
+			"- `~A~`coverd[^1]✅\n" +
+			"- `~B~`[^2]\n" +
+			"- `~C~`uncvrd[^3]✅\n" +
+			"- `~D~`covered[^4]✅\n" +
+			"\n" +
+			"[^1]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n" +
+			"[^2]: `[~other/B~impl]`\n" +
+			"[^3]: `[~pkg/C~impl]`\n" +
+			"[^4]: `[~pkg/D~impl]` [d.go:1:impl](https://example.com/d.go#L1)\n"
+
+		fixed, fixes, err := FixContent(&ScannerContext{}, "req.md", []byte(content))
+		require.NoError(t, err)
+		require.Equal(t, mdHeader+
+			"- `~A~`covrd[^1]✅\n"+
+			"- `~B~`uncvrd[^2]\n"+
+			"- `~C~`uncvrd[^3]❓\n"+
+			"- `~D~`covrd[^4]✅\n"+
+			"\n"+
+			"[^1]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n"+
+			"[^2]: `[~pkg/B~impl]`\n"+
+			"[^3]: `[~pkg/C~impl]`\n"+
+			"[^4]: `[~pkg/D~impl]` [d.go:1:impl](https://example.com/d.go#L1)\n", string(fixed))
+
+		require.Len(t, fixes, 5)
+		for _, fix := range fixes {
+			require.True(t, fix.Safe, fix.String())
+		}
+		require.Equal(t, []string{"covstatus", "covstatus", "statusemoji", "covstatus", "pkgmismatch"},
+			[]string{fixes[0].Code, fixes[1].Code, fixes[2].Code, fixes[3].Code, fixes[4].Code})
+		require.Equal(t, "5: covstatus: CoverageStatusWord is set to 'covrd'", fixes[0].String())
+
+		// Fixed content has nothing to fix
+		fixed2, fixes, err := FixContent(&ScannerContext{}, "req.md", fixed)
+		require.NoError(t, err)
+		require.Empty(t, fixes)
+		require.Equal(t, fixed, fixed2)
+	})
+
+	t.Run("suggestions", func(t *testing.T) {
+		content := mdHeader +
+			"- `~Post-handler~`\n" +
+			"- `~A~` and `~B~`\n"
+
+		fixed, fixes, err := FixContent(&ScannerContext{}, "req.md", []byte(content))
+		require.NoError(t, err)
+		require.Equal(t, content, string(fixed))
+		require.Len(t, fixes, 2)
+		require.Equal(t, "5: reqident: suggestion: rename 'Post-handler' to 'Post_handler' here and in CoverageTags", fixes[0].String())
+		require.Equal(t, "6: multisites: suggestion: split the line so that every RequirementSite is on its own line: `~A~`, `~B~`", fixes[1].String())
+	})
+
+	t.Run("crlf", func(t *testing.T) {
+		content := "---\r\nreqmd.package: pkg\r\n---\r\n\r\n- `~A~`covered[^1]✅\r\n"
+		fixed, fixes, err := FixContent(&ScannerContext{}, "req.md", []byte(content))
+		require.NoError(t, err)
+		require.Len(t, fixes, 1)
+		require.Equal(t, "---\r\nreqmd.package: pkg\r\n---\r\n\r\n- `~A~`covrd[^1]✅\r\n", string(fixed))
+	})
+
+	t.Run("long line", func(t *testing.T) {
+		// Lines over the limit of bufio.Scanner can not be parsed
+		content := mdHeader + "- `~A~`covered[^1]✅\n" + strings.Repeat("a", 70*1024) + "\n"
+		_, _, err := FixContent(&ScannerContext{}, "req.md", []byte(content))
+		require.ErrorContains(t, err, "req.md: Error reading file")
+	})
+}
+
+func TestToIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Post-handler", "Post_handler"},
+		{"cmp.my-req", "cmp.my_req"},
+		{"1st", "R1st"},
+		{"a b.c--d", "a_b.c_d"},
+		{"-", ""},
+		{"a..b", ""},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, toIdentifier(tt.name), tt.name)
+	}
+}
+
+func TestFixFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writeMd(t, dir, "req.md", "- `~A~`covered[^1]✅\n")
+	read := func() string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+	content := read()
+
+	var out, errOut bytes.Buffer
+
+	// Dry run with diff
+	suggestions, err := FixFiles(&FixConfig{DryRun: true, Diff: true}, []string{dir}, &out, &errOut)
+	require.NoError(t, err)
+	require.Zero(t, suggestions)
+	require.Contains(t, out.String(), "-- `~A~`covered[^1]✅\n+- `~A~`covrd[^1]✅\n")
+	require.Empty(t, errOut.String())
+	require.Equal(t, content, read())
+
+	out.Reset()
+	suggestions, err = FixFiles(&FixConfig{}, []string{dir}, &out, &errOut)
+	require.NoError(t, err)
+	require.Zero(t, suggestions)
+	require.Contains(t, out.String(), "req.md:5: covstatus: CoverageStatusWord 'covered' is migrated to 'covrd'\n")
+	require.Equal(t, mdHeader+"- `~A~`covrd[^1]✅\n", read())
+
+	// Suggestions are counted, files with safe fixes only are written
+	writeMd(t, dir, "multi.md", "- `~B~` `~C~`\n")
+	out.Reset()
+	suggestions, err = FixFiles(&FixConfig{Diff: true}, []string{dir}, &out, &errOut)
+	require.NoError(t, err)
+	require.Equal(t, 1, suggestions)
+	require.Empty(t, out.String())
+	require.Contains(t, errOut.String(), "multi.md:5: multisites: suggestion:")
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"strconv"
+)
+
+// NewFormatter returns IAnalyzer that normalizes coverage footnotes of markdown files using numeric ids, ref. NewFormatterEx()
+func NewFormatter() IAnalyzer {
+	return NewFormatterEx(&AnalyzerConfig{})
+}
+
+// NewFormatterEx returns IAnalyzer that normalizes coverage footnotes of markdown files:
+//   - CoverageFootnoteIds are renumbered in order of RequirementSite appearance or derived from RequirementNames, according to acfg.FootnoteIds.
+//     RequirementSites are rewritten to match, so it is also the migration between strategies
+//   - Coverage footnotes are moved into one block at the end of the file, in order of RequirementSite appearance
+//   - Orphaned coverage footnotes are removed
+//   - Footnotes that are not generated by reqmd or are referenced by RequirementSites on ignored lines are kept intact, their ids are not used
+func NewFormatterEx(acfg *AnalyzerConfig) IAnalyzer {
+	return &formatter{footnoteIds: acfg.FootnoteIds}
+}
+
+type formatter struct {
+	footnoteIds FootnoteIdStrategy
+}
+
+func (f *formatter) Analyze(files []FileStructure) (*AnalyzerResult, error) {
+	result := &AnalyzerResult{
+		MdActions: make(map[FilePath][]MdAction),
+	}
+	for i := range files {
+		file := &files[i]
+		if file.Type != FileTypeMarkdown || file.PackageId == "" {
+			continue
+		}
+		if actions := formatMdActions(file, f.footnoteIds); len(actions) > 0 {
+			result.MdActions[file.Path] = actions
+		}
+	}
+	return result, nil
+}
+
+// formatMdActions returns actions that format the file, nil if the file is already formatted
+func formatMdActions(file *FileStructure, footnoteIds FootnoteIdStrategy) []MdAction {
+	// Ids of footnotes that are not generated by reqmd or are referenced on ignored lines
+	reserved := make(map[CoverageFootnoteId]bool)
+	footnotes := make(map[CoverageFootnoteId]*CoverageFootnote)
+	var coverageFootnotes []*CoverageFootnote
+	for i := range file.CoverageFootnotes {
+		cf := &file.CoverageFootnotes[i]
+		if cf.PackageId == "" || file.IgnoredFootnoteRefs[cf.CoverageFootnoteId] {
+			reserved[cf.CoverageFootnoteId] = true
+			continue
+		}
+		coverageFootnotes = append(coverageFootnotes, cf)
+		if _, ok := footnotes[cf.CoverageFootnoteId]; !ok {
+			footnotes[cf.CoverageFootnoteId] = cf
+		}
+	}
+
+	// New ids in order of site appearance
+	newIds := make(map[CoverageFootnoteId]CoverageFootnoteId)
+	var ordered []CoverageFootnoteId // old ids of footnotes in the new order
+	nextId := 0
+	formatted := true
+	for _, site := range file.Requirements {
+		if !site.HasAnnotationRef {
+			continue
+		}
+		if _, ok := newIds[site.CoverageFootnoteId]; ok {
+			continue
+		}
+		var newId CoverageFootnoteId
+		if footnoteIds == FootnoteIdsName {
+			newId = NamedFootnoteId(site.RequirementName)
+		} else {
+			for {
+				nextId++
+				newId = CoverageFootnoteId(strconv.Itoa(nextId))
+				if !reserved[newId] {
+					break
+				}
+			}
+		}
+		newIds[site.CoverageFootnoteId] = newId
+		if newId != site.CoverageFootnoteId || site.CoverageStatusEmoji != CoverageStatusEmojiOf(site.CoverageStatusWord) {
+			formatted = false
+		}
+		if _, ok := footnotes[site.CoverageFootnoteId]; ok {
+			ordered = append(ordered, site.CoverageFootnoteId)
+		}
+	}
+
+	// Coverage footnotes shall be the last lines of the file, in order, without orphaned and duplicated ones
+	if formatted {
+		formatted = len(ordered) == len(coverageFootnotes)
+		for i, id := range ordered {
+			if !formatted {
+				break
+			}
+			cf := footnotes[id]
+			formatted = cf == coverageFootnotes[i] &&
+				(i == 0 || cf.Line == coverageFootnotes[i-1].Line+1) &&
+				(i < len(ordered)-1 || cf.Line == file.LastLine)
+		}
+	}
+	if formatted {
+		return nil
+	}
+
+	var actions []MdAction
+	for _, site := range file.Requirements {
+		newId, ok := newIds[site.CoverageFootnoteId]
+		if !site.HasAnnotationRef || !ok {
+			continue
+		}
+		if newId == site.CoverageFootnoteId && site.CoverageStatusEmoji == CoverageStatusEmojiOf(site.CoverageStatusWord) {
+			continue
+		}
+		actions = append(actions, MdAction{
+			Type:            ActionSite,
+			Path:            file.Path,
+			Line:            site.Line,
+			RequirementName: site.RequirementName,
+			Data:            FormatRequirementSite(site.RequirementName, site.CoverageStatusWord, newId),
+		})
+	}
+	for _, cf := range coverageFootnotes {
+		actions = append(actions, MdAction{
+			Type:            ActionFootnoteRemove,
+			Path:            file.Path,
+			Line:            cf.Line,
+			RequirementName: cf.RequirementName,
+		})
+	}
+	for _, id := range ordered {
+		cf := *footnotes[id]
+		cf.CoverageFootnoteId = newIds[id]
+		actions = append(actions, MdAction{
+			Type:            ActionFootnote,
+			Path:            file.Path,
+			RequirementName: cf.RequirementName,
+			Data:            FormatCoverageFootnote(&cf),
+		})
+	}
+	return actions
+}
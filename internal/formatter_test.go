@@ -0,0 +1,157 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// formatContent parses the content, formats it using numeric ids and applies actions in memory
+func formatContent(t *testing.T, content string) (string, []MdAction) {
+	return formatContentEx(t, content, FootnoteIdsNumeric)
+}
+
+func formatContentEx(t *testing.T, content string, footnoteIds FootnoteIdStrategy) (string, []MdAction) {
+	structure, errs, err := parseFileEx(&ScannerContext{}, "req.md", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Empty(t, errs)
+
+	res, err := NewFormatterEx(&AnalyzerConfig{FootnoteIds: footnoteIds}).Analyze([]FileStructure{*structure})
+	require.NoError(t, err)
+	actions := res.MdActions["req.md"]
+	if len(actions) == 0 {
+		return content, nil
+	}
+	updated, err := applyMdActions("req.md", []byte(content), actions)
+	require.NoError(t, err)
+	return string(updated), actions
+}
+
+func TestFormatter(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+	content := header +
+		"- `~A~`covrd[^5]✅\n" +
+		"\n" +
+		"[^5]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n" +
+		"[^1]: A regular footnote\n" +
+		"\n" +
+		"- `~B~`uncvrd[^2]❓\n" +
+		"- `~C~`covrd[^~C~]✅\n" +
+		"\n" +
+		"[^~C~]: `[~pkg/C~impl]` [c.go:3:impl](https://example.com/c.go#L3)\n" +
+		"[^2]: `[~pkg/B~impl]`\n" +
+		"[^9]: `[~pkg/Removed~impl]`\n"
+
+	formatted, actions := formatContent(t, content)
+	require.NotEmpty(t, actions)
+
+	// Id 1 is used by the regular footnote, the orphaned footnote is removed
+	require.Equal(t, header+
+		"- `~A~`covrd[^2]✅\n"+
+		"\n"+
+		"[^1]: A regular footnote\n"+
+		"\n"+
+		"- `~B~`uncvrd[^3]❓\n"+
+		"- `~C~`covrd[^4]✅\n"+
+		"\n"+
+		"[^2]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n"+
+		"[^3]: `[~pkg/B~impl]`\n"+
+		"[^4]: `[~pkg/C~impl]` [c.go:3:impl](https://example.com/c.go#L3)\n",
+		formatted)
+
+	// Formatted content is not changed
+	_, actions = formatContent(t, formatted)
+	require.Empty(t, actions)
+}
+
+func TestFormatter_Formatted(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+
+	t.Run("footnotes are not at the end", func(t *testing.T) {
+		content := header +
+			"- `~A~`uncvrd[^1]❓\n" +
+			"\n" +
+			"[^1]: `[~pkg/A~impl]`\n" +
+			"\n" +
+			"Trailing text\n"
+		formatted, _ := formatContent(t, content)
+		require.Equal(t, header+"- `~A~`uncvrd[^1]❓\n\nTrailing text\n\n[^1]: `[~pkg/A~impl]`\n", formatted)
+	})
+
+	t.Run("footnotes are not sorted", func(t *testing.T) {
+		content := header +
+			"- `~A~`uncvrd[^1]❓\n" +
+			"- `~B~`uncvrd[^2]❓\n" +
+			"\n" +
+			"[^2]: `[~pkg/B~impl]`\n" +
+			"[^1]: `[~pkg/A~impl]`\n"
+		formatted, _ := formatContent(t, content)
+		require.Equal(t, header+"- `~A~`uncvrd[^1]❓\n- `~B~`uncvrd[^2]❓\n\n[^1]: `[~pkg/A~impl]`\n[^2]: `[~pkg/B~impl]`\n", formatted)
+	})
+
+	t.Run("bare sites and files without footnotes", func(t *testing.T) {
+		content := header + "- `~A~`\n"
+		_, actions := formatContent(t, content)
+		require.Empty(t, actions)
+	})
+}
+
+func TestFormatter_Migration(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+	numeric := header +
+		"- `~A~`covrd[^1]✅\n" +
+		"- `~B~`uncvrd[^2]❓\n" +
+		"\n" +
+		"[^1]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n" +
+		"[^2]: `[~pkg/B~impl]`\n"
+	named := header +
+		"- `~A~`covrd[^~A~]✅\n" +
+		"- `~B~`uncvrd[^~B~]❓\n" +
+		"\n" +
+		"[^~A~]: `[~pkg/A~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n" +
+		"[^~B~]: `[~pkg/B~impl]`\n"
+
+	formatted, _ := formatContentEx(t, numeric, FootnoteIdsName)
+	require.Equal(t, named, formatted)
+	_, actions := formatContentEx(t, named, FootnoteIdsName)
+	require.Empty(t, actions)
+
+	formatted, _ = formatContentEx(t, named, FootnoteIdsNumeric)
+	require.Equal(t, numeric, formatted)
+}
+
+func TestFormatter_IgnoredLines(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+	content := header +
+		"- `~A~`uncvrd[^2]❓\n" +
+		"// line: `~B~`uncvrd[^1]❓\n" +
+		"\n" +
+		"[^1]: `[~pkg/B~impl]`\n" +
+		"[^2]: `[~pkg/A~impl]`\n" +
+		"[^3]: `[~pkg/Removed~impl]`\n"
+
+	sctx := &ScannerContext{IgnorePatterns: []*regexp.Regexp{regexp.MustCompile(`^// line:`)}}
+	structure, errs, err := parseFileEx(sctx, "req.md", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	require.Equal(t, map[CoverageFootnoteId]bool{"1": true}, structure.IgnoredFootnoteRefs)
+
+	res, err := NewFormatter().Analyze([]FileStructure{*structure})
+	require.NoError(t, err)
+	formatted, err := applyMdActions("req.md", []byte(content), res.MdActions["req.md"])
+	require.NoError(t, err)
+
+	// The footnote of the ignored site is kept with its id, the orphaned footnote is removed
+	require.Equal(t, header+
+		"- `~A~`uncvrd[^2]❓\n"+
+		"// line: `~B~`uncvrd[^1]❓\n"+
+		"\n"+
+		"[^1]: `[~pkg/B~impl]`\n"+
+		"[^2]: `[~pkg/A~impl]`\n",
+		string(formatted))
+}
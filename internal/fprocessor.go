@@ -4,9 +4,11 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -14,12 +16,31 @@ import (
 // for handling files within that folder. If an error occurs during folder processing,
 // it returns the error and a nil FileProcessor.
 // The folder path provided is always absolute.
-type FolderProcessor func(absFolderPath string) (FileProcessor, error)
+type FolderProcessor func(ctx context.Context, absFolderPath string) (FileProcessor, error)
 
 // FileProcessor is a function type that processes a single file and returns an error
 // if the processing fails.
 // The file path provided is always absolute.
-type FileProcessor func(absFilePath string) error
+type FileProcessor func(ctx context.Context, absFilePath string) error
+
+// ScanError is an error of processing the folder or the file at Path
+type ScanError struct {
+	Path string
+	Err  error
+}
+
+func (e *ScanError) Error() string {
+	msg := e.Err.Error()
+	// Errors of the os package already contain the path
+	if strings.Contains(msg, e.Path) {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
 
 // FoldersScanner performs concurrent processing of files in a directory tree.
 // It traverses the directory structure in breadth-first order, using FolderProcessor
@@ -27,22 +48,24 @@ type FileProcessor func(absFilePath string) error
 // a pool of goroutines. All paths passed to processors are absolute paths.
 //
 // Parameters:
+//   - ctx: Scanning stops when ctx is done, files that are not processed yet are skipped
 //   - nroutines: Number of concurrent goroutines for file processing (must be > 0)
-//   - nerrors: Maximum number of errors to buffer in the error channel
 //   - root: Root directory path to start scanning from (will be converted to absolute)
 //   - fp: FolderProcessor function to process folders and obtain FileProcessors
+//   - failFast: Stop scanning at the first error of a folder or a file
 //
 // Returns:
-//   - []error: Slice containing all errors encountered during scanning and processing.
+//   - []error: Slice containing all errors encountered during scanning and processing, as *ScanError.
 //     Returns nil if no errors occurred.
 //
 // Behavior:
 //   - Traverses directories breadth-first to maintain predictable processing order
 //   - Processes files concurrently using a worker pool of size nroutines
-//   - Collects all errors from both folder and file processing
+//   - Collects all errors from both folder and file processing, no error is dropped
 //   - Stops folder processing on folder processor error but continues with other folders
-//   - Continues processing even if some files fail, collecting all errors
-func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor) []error {
+//   - Continues processing even if some files fail, collecting all errors, unless failFast is set
+//   - ctx.Err() is not added to the errors, callers check ctx themselves
+func FoldersScanner(ctx context.Context, nroutines int, root string, fp FolderProcessor, failFast bool) []error {
 	if nroutines < 1 {
 		return []error{fmt.Errorf("number of routines must be positive")}
 	}
@@ -53,15 +76,27 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 		return []error{fmt.Errorf("failed to get absolute path: %v", err)}
 	}
 
+	// Canceled on the first error if failFast is set
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Channel for collecting file processors
 	fileProcessors := make(chan struct {
 		processor FileProcessor
 		path      string
 	})
 
-	// Channel for collecting errors
-	errorsChan := make(chan error, nerrors)
+	// Errors of workers and folders
 	var errors []error
+	var errorsMu sync.Mutex
+	addError := func(path string, err error) {
+		errorsMu.Lock()
+		errors = append(errors, &ScanError{Path: path, Err: err})
+		errorsMu.Unlock()
+		if failFast {
+			cancel()
+		}
+	}
 
 	// Start worker pool
 	var wg sync.WaitGroup
@@ -70,12 +105,13 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 		go func() {
 			defer wg.Done()
 			for task := range fileProcessors {
-				if err := task.processor(task.path); err != nil {
-					select {
-					case errorsChan <- err:
-					default:
-						// Channel is full, log or handle accordingly
-					}
+				// Remaining tasks are drained without processing
+				if ctx.Err() != nil {
+					continue
+				}
+				// Errors after cancellation are caused by it or are not of interest
+				if err := task.processor(ctx, task.path); err != nil && ctx.Err() == nil {
+					addError(task.path, err)
 				}
 			}
 		}()
@@ -83,18 +119,18 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 
 	// Process folders breadth-first
 	folders := []string{absRoot}
-	for len(folders) > 0 {
+traversal:
+	for len(folders) > 0 && ctx.Err() == nil {
 		currentFolder := folders[0]
 		folders = folders[1:]
 
 		// Get file processor for current folder
-		fileProcessor, err := fp(currentFolder)
+		fileProcessor, err := fp(ctx, currentFolder)
 		if err != nil {
-			select {
-			case errorsChan <- err:
-			default:
-				// Channel is full, log or handle accordingly
+			if ctx.Err() != nil {
+				break
 			}
+			addError(currentFolder, err)
 			continue
 		}
 
@@ -106,11 +142,7 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 		// Read directory entries
 		entries, err := os.ReadDir(currentFolder)
 		if err != nil {
-			select {
-			case errorsChan <- err:
-			default:
-				// Channel is full, log or handle accordingly
-			}
+			addError(currentFolder, err)
 			continue
 		}
 
@@ -126,10 +158,14 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 					Verbose("FoldersScanner: entry", path)
 				}
 				// Send file to processing pool
-				fileProcessors <- struct {
+				select {
+				case fileProcessors <- struct {
 					processor FileProcessor
 					path      string
-				}{fileProcessor, path}
+				}{fileProcessor, path}:
+				case <-ctx.Done():
+					break traversal
+				}
 			}
 		}
 	}
@@ -138,11 +174,5 @@ func FoldersScanner(nroutines int, nerrors int, root string, fp FolderProcessor)
 	close(fileProcessors)
 	wg.Wait()
 
-	// Collect all errors
-	close(errorsChan)
-	for err := range errorsChan {
-		errors = append(errors, err)
-	}
-
 	return errors
 }
@@ -3,6 +3,7 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -140,7 +142,7 @@ func TestFoldersScanner(t *testing.T) {
 			var mu sync.Mutex
 
 			// Create folder processor
-			fp := func(folder string) (FileProcessor, error) {
+			fp := func(_ context.Context, folder string) (FileProcessor, error) {
 				// Verify folder path is absolute
 				if tt.name == "verify absolute paths" && !filepath.IsAbs(folder) {
 					t.Errorf("Expected absolute folder path, got: %s", folder)
@@ -159,7 +161,7 @@ func TestFoldersScanner(t *testing.T) {
 					return nil, nil
 				}
 
-				return func(filePath string) error {
+				return func(_ context.Context, filePath string) error {
 					// Verify file path is absolute
 					if tt.name == "verify absolute paths" && !filepath.IsAbs(filePath) {
 						t.Errorf("Expected absolute file path, got: %s", filePath)
@@ -178,7 +180,7 @@ func TestFoldersScanner(t *testing.T) {
 			}
 
 			// Run scanner
-			errs := FoldersScanner(tt.nroutines, 100, absRoot, fp)
+			errs := FoldersScanner(t.Context(), tt.nroutines, absRoot, fp, false)
 
 			// Verify results
 			if tt.expectErrors && len(errs) == 0 {
@@ -247,7 +249,7 @@ func TestFoldersScanner(t *testing.T) {
 	}
 }
 
-// TestFoldersScanner_ALotOfErrors tests the FoldersScanner function with a large number of errors that more than the error channel capacity
+// TestFoldersScanner_ALotOfErrors tests that the FoldersScanner function collects a large number of errors
 func TestFoldersScanner_ALotOfErrors(t *testing.T) {
 	// Create temporary root directory
 	root := t.TempDir()
@@ -299,8 +301,8 @@ func TestFoldersScanner_ALotOfErrors(t *testing.T) {
 	var mu sync.Mutex
 
 	// Create folder processor that generates an error for every file
-	fp := func(folder string) (FileProcessor, error) {
-		return func(filePath string) error {
+	fp := func(_ context.Context, folder string) (FileProcessor, error) {
+		return func(_ context.Context, filePath string) error {
 			mu.Lock()
 			processedCount++
 			mu.Unlock()
@@ -308,21 +310,71 @@ func TestFoldersScanner_ALotOfErrors(t *testing.T) {
 		}, nil
 	}
 
-	// Run scanner with small error channel capacity
-	errs := FoldersScanner(4, 10, absRoot, fp)
+	errs := FoldersScanner(t.Context(), 4, absRoot, fp, false)
 
-	// Verify that we processed all files despite error channel being full
+	// Verify that we processed all files
 	mu.Lock()
 	if processedCount != 100 { // 50 files in each directory
 		t.Errorf("Expected 100 processed files, got %d", processedCount)
 	}
 	mu.Unlock()
 
-	// Verify that we got some errors but not necessarily all of them
-	if len(errs) == 0 {
-		t.Error("Expected some errors but got none")
+	// Verify that no error is dropped and every error has the path
+	if len(errs) != 100 {
+		t.Errorf("Expected 100 errors, got %d", len(errs))
 	}
-	if len(errs) > 10 {
-		t.Errorf("Expected at most 10 errors (channel capacity), got %d", len(errs))
+	for _, err := range errs {
+		var scanErr *ScanError
+		if !errors.As(err, &scanErr) || scanErr.Path == "" {
+			t.Errorf("Expected ScanError with the path, got %v", err)
+		}
+	}
+}
+
+func TestFoldersScanner_Context(t *testing.T) {
+	root := t.TempDir()
+	structure := testStructure{name: "root", isDir: true}
+	for i := 0; i < 10; i++ {
+		structure.children = append(structure.children, testStructure{
+			name:    fmt.Sprintf("file%d.txt", i),
+			content: fmt.Sprintf("content%d", i),
+		})
+	}
+	if err := createTestStructure(t, root, structure); err != nil {
+		t.Fatalf("Failed to create test structure: %v", err)
 	}
+
+	var processedCount atomic.Int32
+	fp := func(_ context.Context, folder string) (FileProcessor, error) {
+		return func(_ context.Context, filePath string) error {
+			processedCount.Add(1)
+			return fmt.Errorf("error processing file: %s", filePath)
+		}, nil
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		processedCount.Store(0)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		errs := FoldersScanner(ctx, 4, root, fp, false)
+		if len(errs) != 0 || processedCount.Load() != 0 {
+			t.Errorf("Expected nothing processed, got %d errors and %d processed files", len(errs), processedCount.Load())
+		}
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		processedCount.Store(0)
+		errs := FoldersScanner(t.Context(), 1, root, fp, true)
+		if len(errs) != 1 || processedCount.Load() != 1 {
+			t.Errorf("Expected 1 error and 1 processed file, got %d errors and %d processed files", len(errs), processedCount.Load())
+		}
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		processedCount.Store(0)
+		errs := FoldersScanner(t.Context(), 1, root, fp, false)
+		if len(errs) != 10 || processedCount.Load() != 10 {
+			t.Errorf("Expected 10 errors and 10 processed files, got %d errors and %d processed files", len(errs), processedCount.Load())
+		}
+	})
 }
@@ -0,0 +1,254 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// gitProvider describes how file URLs are constructed by a git hosting provider
+type gitProvider struct {
+	names    []string // the first name is the primary one, others are aliases
+	hosts    []string // hosts and their subdomains that are served by the provider
+	template string   // URL template, ref. URLPlaceholder*
+	// URL template that is used if {ref} is a commit hash, template is used if empty
+	commitTemplate string
+}
+
+var gitProviders = []gitProvider{
+	{
+		names:    []string{"github"},
+		hosts:    []string{"github.com"},
+		template: "{base}/blob/{ref}/{path}#L{line}",
+	},
+	{
+		names:    []string{"gitlab"},
+		hosts:    []string{"gitlab.com"},
+		template: "{base}/-/blob/{ref}/{path}#L{line}",
+	},
+	{
+		names:    []string{"bitbucket"},
+		hosts:    []string{"bitbucket.org"},
+		template: "{base}/src/{ref}/{path}#lines-{line}",
+	},
+	{
+		names:          []string{"azure"},
+		hosts:          []string{"dev.azure.com", "visualstudio.com"},
+		template:       "{base}?path=/{path}&version=GB{ref}&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+		commitTemplate: "{base}?path=/{path}&version=GC{ref}&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+	},
+	{
+		names:          []string{"gitea", "forgejo"},
+		hosts:          []string{"gitea.com", "codeberg.org"},
+		template:       "{base}/src/branch/{ref}/{path}#L{line}",
+		commitTemplate: "{base}/src/commit/{ref}/{path}#L{line}",
+	},
+	{
+		names:    []string{"sourcehut"},
+		hosts:    []string{"git.sr.ht"},
+		template: "{base}/tree/{ref}/item/{path}#L{line}",
+	},
+	{
+		names:    []string{"gitiles", "gerrit"},
+		hosts:    []string{"googlesource.com"},
+		template: "{base}/+/{ref}/{path}#{line}",
+	},
+}
+
+// findGitProviderByName returns the provider with the given name or alias
+func findGitProviderByName(name string) (*gitProvider, bool) {
+	for i := range gitProviders {
+		for _, n := range gitProviders[i].names {
+			if strings.EqualFold(n, name) {
+				return &gitProviders[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// findGitProviderByHost returns the provider that serves the given host
+func findGitProviderByHost(host string) (*gitProvider, bool) {
+	host = strings.ToLower(host)
+	for i := range gitProviders {
+		for _, h := range gitProviders[i].hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return &gitProviders[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// urlTemplate returns the template for branch or commit refs
+func (p *gitProvider) urlTemplate(commitRef bool) string {
+	if commitRef && p.commitTemplate != "" {
+		return p.commitTemplate
+	}
+	return p.template
+}
+
+// resolveURLTemplate returns the URL template for the repository URL.
+// urlTemplates maps hosts to URL templates or to names of the built-in providers and takes precedence over the built-in hosts.
+// commitRef is true if {ref} is a commit hash.
+func resolveURLTemplate(repoURL string, urlTemplates map[string]string, commitRef bool) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("unsupported git provider: %s", repoURL)
+	}
+
+	for _, host := range []string{u.Host, u.Hostname()} {
+		tmpl, ok := urlTemplates[strings.ToLower(host)]
+		if !ok {
+			continue
+		}
+		if strings.Contains(tmpl, "{") {
+			return tmpl, validateURLTemplate(tmpl)
+		}
+		provider, ok := findGitProviderByName(tmpl)
+		if !ok {
+			return "", fmt.Errorf("unknown git provider for %s: %s", host, tmpl)
+		}
+		return provider.urlTemplate(commitRef), nil
+	}
+
+	provider, ok := findGitProviderByHost(u.Hostname())
+	if !ok {
+		return "", fmt.Errorf("unsupported git provider: %s", repoURL)
+	}
+	return provider.urlTemplate(commitRef), nil
+}
+
+// validateURLTemplate checks that the template refers to the file path
+func validateURLTemplate(tmpl string) error {
+	if !strings.Contains(tmpl, URLPlaceholderPath) {
+		return fmt.Errorf("URL template shall contain %s: %s", URLPlaceholderPath, tmpl)
+	}
+	return nil
+}
+
+// expandRepoURLTemplate replaces repository-wide placeholders, {path} and {line} are kept
+func expandRepoURLTemplate(tmpl, base, ref string) string {
+	return strings.NewReplacer(
+		URLPlaceholderBase, strings.TrimSuffix(base, "/"),
+		URLPlaceholderRef, ref,
+	).Replace(tmpl)
+}
+
+// repoRootFolderURL returns the part of the expanded template that precedes the file path
+func repoRootFolderURL(tmpl string) string {
+	root, _, _ := strings.Cut(tmpl, URLPlaceholderPath)
+	return strings.TrimSuffix(root, "/")
+}
+
+// scp-like syntax of git remotes: [user@]host:path
+var scpLikeRemoteRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// normalizeRemoteURL converts the remote URL to the https URL of the repository:
+//   - scp-like and ssh:// URLs are converted to https:// URLs, ports are dropped
+//   - git:// URLs are converted to https:// URLs
+//   - Userinfo is stripped, withCredentials reports whether it contained a password or a token, Azure DevOps organization user names are not tokens
+//   - ".git" suffix and trailing slashes are stripped
+//   - Azure DevOps ssh paths (v3/org/project/repo) are converted to web paths
+//
+// Local paths, file:// URLs and unknown schemes are rejected.
+func normalizeRemoteURL(remoteURL string) (normalized string, withCredentials bool, err error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, path string
+	scheme := "https"
+
+	if !strings.Contains(remoteURL, "://") {
+		m := scpLikeRemoteRegex.FindStringSubmatch(remoteURL)
+		// A single letter host is a Windows drive, e.g. C:/repos/repo
+		if m == nil || len(m[1]) == 1 {
+			return "", false, fmt.Errorf("unsupported remote URL, local paths are not supported: %s", remoteURL)
+		}
+		host, path = m[1], m[2]
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			// url.Error contains the URL, it may contain credentials
+			return "", false, fmt.Errorf("failed to parse remote URL: %s", redactRemoteURL(remoteURL))
+		}
+		switch u.Scheme {
+		case "https", "http":
+			scheme = u.Scheme
+			host = u.Host
+			if u.User != nil {
+				_, withCredentials = u.User.Password()
+				// A user name without a password is a token for some providers, e.g. https://<token>@github.com,
+				// but Azure DevOps puts the organization into clone URLs, e.g. https://org@dev.azure.com/org/project/_git/repo
+				withCredentials = withCredentials || u.User.Username() != "" && !isAzureOrgUser(u.Hostname(), u.Path, u.User.Username())
+			}
+		case "ssh", "git+ssh", "ssh+git", "git":
+			host = u.Hostname()
+		default:
+			return "", false, fmt.Errorf("unsupported remote URL scheme: %s", redactRemoteURL(remoteURL))
+		}
+		path = u.Path
+	}
+
+	if host == "" {
+		return "", false, fmt.Errorf("no host in remote URL: %s", redactRemoteURL(remoteURL))
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return "", false, fmt.Errorf("no repository path in remote URL: %s", redactRemoteURL(remoteURL))
+	}
+
+	host, path = azureWebPath(host, path)
+	return scheme + "://" + host + "/" + path, withCredentials, nil
+}
+
+// isAzureOrgUser returns true if the user of the https URL is the Azure DevOps organization of the URL
+func isAzureOrgUser(host, path, user string) bool {
+	host = strings.ToLower(host)
+	if org, ok := strings.CutSuffix(host, ".visualstudio.com"); ok {
+		return strings.EqualFold(org, user)
+	}
+	if host != "dev.azure.com" {
+		return false
+	}
+	org, _, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	return strings.EqualFold(org, user)
+}
+
+// azureWebPath converts Azure DevOps ssh hosts and paths to web ones:
+//   - ssh.dev.azure.com, v3/org/project/repo => dev.azure.com, org/project/_git/repo
+//   - vs-ssh.visualstudio.com, v3/org/project/repo => org.visualstudio.com, project/_git/repo
+func azureWebPath(host, path string) (string, string) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[0] != "v3" {
+		return host, path
+	}
+	org, project, repo := parts[1], parts[2], parts[3]
+	switch strings.ToLower(host) {
+	case "ssh.dev.azure.com":
+		return "dev.azure.com", org + "/" + project + "/_git/" + repo
+	case "vs-ssh.visualstudio.com":
+		return org + ".visualstudio.com", project + "/_git/" + repo
+	}
+	return host, path
+}
+
+// redactRemoteURL hides userinfo of the URL so that it can be logged
+func redactRemoteURL(remoteURL string) string {
+	scheme, rest, ok := strings.Cut(remoteURL, "://")
+	if !ok {
+		return remoteURL
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		if slash := strings.Index(rest, "/"); slash < 0 || i < slash {
+			return scheme + "://***@" + rest[i+1:]
+		}
+	}
+	return remoteURL
+}
@@ -4,42 +4,67 @@
 package internal
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	gog "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
+const defaultRemoteName = "origin"
+
+// CI environment variables that are checked (in order) if the commit ref can not be resolved from the repository
+var ciRefEnvVars = []string{
+	// Default and target branches
+	"CI_DEFAULT_BRANCH",                   // GitLab
+	"CI_MERGE_REQUEST_TARGET_BRANCH_NAME", // GitLab
+	"GITHUB_BASE_REF",                     // GitHub Actions, pull requests
+	"BITBUCKET_PR_DESTINATION_BRANCH",     // Bitbucket Pipelines
+	"SYSTEM_PULLREQUEST_TARGETBRANCH",     // Azure Pipelines
+	// Current branches
+	"GITHUB_REF_NAME",    // GitHub Actions
+	"CI_COMMIT_BRANCH",   // GitLab
+	"BITBUCKET_BRANCH",   // Bitbucket Pipelines
+	"BUILD_SOURCEBRANCH", // Azure Pipelines
+}
+
+// GitConfig configures how file URLs are constructed
+type GitConfig struct {
+	// Maps hosts to URL templates or to names of the built-in providers, e.g.
+	// "git.example.com": "gitlab", "code.example.com": "{base}/browse/{path}?at={ref}#{line}"
+	URLTemplates map[string]string
+	Remote       string // name of the remote, "origin" if empty
+	Ref          string // CommitRef, resolved from the repository if empty, ref. resolveCommitRef()
+	Permalinks   bool   // Use the HEAD commit hash as CommitRef, Ref shall be empty
+	// Files that are not committed are processed, their hashes are computed from the working tree.
+	// Files that are ignored by .gitignore are not processed
+	IncludeUntracked bool
+	// Hashes are read from the index rather than from the HEAD commit, so staged files are processed
+	Staged bool
+	// Content of the files is read from the index rather than from the working tree, implies Staged
+	StagedContent bool
+}
+
 func NewGitVCS(path string) (IVCS, error) {
+	return NewGitVCSEx(path, &GitConfig{})
+}
 
-	// Find path to the root of the git repository
-	{
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get absolute path: %w", err)
-		}
-		absPath = filepath.ToSlash(absPath)
-		var gitPath string
-		currentPath := absPath
-		for {
-			if _, err := os.Stat(filepath.Join(currentPath, gitFolderName)); err == nil {
-				gitPath = currentPath
-				break
-			}
-			parent := filepath.Dir(currentPath)
-			if parent == currentPath {
-				return nil, fmt.Errorf("no git repository found for path: %s", path)
-			}
-			currentPath = parent
-		}
-		path = gitPath
+func NewGitVCSEx(path string, gcfg *GitConfig) (IVCS, error) {
+
+	path, err := findGitRoot(path)
+	if err != nil {
+		return nil, err
 	}
 
-	repo, err := gog.PlainOpen(path)
+	repo, err := openGitRepo(path)
 	if err != nil {
 		return nil, err
 	}
@@ -58,29 +83,83 @@ func NewGitVCS(path string) (IVCS, error) {
 	}
 
 	g := &git{
-		pathToRoot: path,
-		repo:       repo,
-		commit:     commit,
-		tree:       tree,
+		pathToRoot:       path,
+		repo:             repo,
+		commit:           commit,
+		tree:             tree,
+		includeUntracked: gcfg.IncludeUntracked,
+		stagedContent:    gcfg.StagedContent,
+	}
+
+	if gcfg.Staged || gcfg.StagedContent {
+		if g.index, err = readGitIndex(repo, path); err != nil {
+			return nil, fmt.Errorf("failed to read git index: %w", err)
+		}
 	}
 
-	if err := g.constructRepoRootFolderURL(); err != nil {
+	if gcfg.IncludeUntracked {
+		wt, err := repo.Worktree()
+		if err != nil {
+			return nil, err
+		}
+		patterns, err := gitignore.ReadPatterns(wt.Filesystem, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .gitignore files: %w", err)
+		}
+		g.ignoreMatcher = gitignore.NewMatcher(patterns)
+	}
+
+	if err := g.constructRepoRootFolderURL(gcfg); err != nil {
 		return nil, fmt.Errorf("failed to construct repo URL: %w", err)
 	}
 	return g, nil
 }
 
+// findGitRoot returns the slashed absolute path to the root of the git repository that contains path.
+// .git is a folder for regular repositories and a file for linked worktrees and submodules
+func findGitRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	currentPath := filepath.ToSlash(absPath)
+	for {
+		if _, err := os.Stat(filepath.Join(currentPath, gitFolderName)); err == nil {
+			return currentPath, nil
+		}
+		parent := filepath.Dir(currentPath)
+		if parent == currentPath {
+			return "", fmt.Errorf("no git repository found for path: %s", path)
+		}
+		currentPath = parent
+	}
+}
+
+// openGitRepo opens the repository, root is the folder that contains .git
+func openGitRepo(root string) (*gog.Repository, error) {
+	// Linked worktrees keep objects and refs in the common dir of the main repository
+	return gog.PlainOpenWithOptions(root, &gog.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
 type git struct {
 	pathToRoot        string
 	repo              *gog.Repository
 	commit            *object.Commit
 	tree              *object.Tree
-	repoRootFolderURL string // Cached during initialization
+	index             *index.Index      // Not nil if GitConfig.Staged
+	includeUntracked  bool              // GitConfig.IncludeUntracked
+	stagedContent     bool              // GitConfig.StagedContent
+	ignoreMatcher     gitignore.Matcher // Not nil if GitConfig.IncludeUntracked
+	repoRootFolderURL string            // Cached during initialization
+	urlTemplate       string            // Cached during initialization, ref. CoverageURLTemplate
 	mu                sync.RWMutex
 }
 
 // Returns the hash of a file in the git repository.
 // filePath is not necessary relative to the repository root.
+// The hash is taken from the HEAD commit or from the index (GitConfig.Staged),
+// if the file is not there it is computed from the working tree (GitConfig.IncludeUntracked).
+// Error describes why the file is considered untracked.
 func (g *git) FileHash(filePath string) (relPath, hash string, err error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -89,65 +168,277 @@ func (g *git) FileHash(filePath string) (relPath, hash string, err error) {
 	if err != nil {
 		return "", "", err
 	}
-	file, err := g.tree.FindEntry(relPath)
+
+	if g.index != nil {
+		if entry, err := g.index.Entry(relPath); err == nil {
+			return relPath, entry.Hash.String(), nil
+		}
+	} else if file, err := g.tree.FindEntry(relPath); err == nil {
+		return relPath, file.Hash.String(), nil
+	}
+
+	if !g.includeUntracked {
+		if g.index != nil {
+			return "", "", fmt.Errorf("not staged (use --include-untracked to process it)")
+		}
+		return "", "", fmt.Errorf("not committed (use --staged or --include-untracked to process it)")
+	}
+	if g.ignoreMatcher.Match(strings.Split(relPath, "/"), false) {
+		return "", "", fmt.Errorf("ignored by .gitignore")
+	}
+	hash, err = fileBlobHash(filePath)
 	if err != nil {
 		return "", "", err
 	}
-	return relPath, file.Hash.String(), nil
+	return relPath, hash, nil
+}
+
+// gitIndexFile returns the absolute path to GIT_INDEX_FILE if it is set for the repository with the given root, empty otherwise.
+// `git commit -a` and `git commit <paths>` pass a temporary index to hooks, git runs hooks in the root of the working tree,
+// so GIT_INDEX_FILE belongs to the repository of the current folder
+func gitIndexFile(root string) (string, error) {
+	indexFile := os.Getenv("GIT_INDEX_FILE")
+	if indexFile == "" {
+		return "", nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if wdRoot, err := findGitRoot(wd); err != nil || wdRoot != root {
+		return "", nil
+	}
+	return filepath.Abs(indexFile)
+}
+
+// readGitIndex reads the index of the repository, ref. gitIndexFile()
+func readGitIndex(repo *gog.Repository, root string) (*index.Index, error) {
+	indexFile, err := gitIndexFile(root)
+	if err != nil {
+		return nil, err
+	}
+	if indexFile == "" {
+		return repo.Storer.Index()
+	}
+	f, err := os.Open(indexFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	idx := &index.Index{}
+	if err := index.NewDecoder(f).Decode(idx); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexFile, err)
+	}
+	return idx, nil
+}
+
+// writeGitIndex writes the index of the repository, ref. gitIndexFile()
+func writeGitIndex(repo *gog.Repository, root string, idx *index.Index) error {
+	indexFile, err := gitIndexFile(root)
+	if err != nil {
+		return err
+	}
+	if indexFile == "" {
+		return repo.Storer.SetIndex(idx)
+	}
+	var buf bytes.Buffer
+	if err := index.NewEncoder(&buf).Encode(idx); err != nil {
+		return err
+	}
+	tempPath, err := writeTempFile(indexFile, buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, indexFile); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+func (g *git) ReadFile(filePath string) ([]byte, error) {
+	if !g.stagedContent {
+		return os.ReadFile(filePath)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	relPath, err := filepath.Rel(g.PathToRoot(), filePath)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := g.index.Entry(filepath.ToSlash(relPath))
+	if err != nil {
+		// Untracked file, ref. GitConfig.IncludeUntracked
+		return os.ReadFile(filePath)
+	}
+	content, err := readBlob(g.repo, entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged content of %s: %w", relPath, err)
+	}
+	return content, nil
+}
+
+// readStagedFile returns the staged content of the file, ref. readGitIndex()
+func readStagedFile(path string) ([]byte, error) {
+	root, err := findGitRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := openGitRepo(root)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := readGitIndex(repo, root)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := idx.Entry(filepath.ToSlash(relPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged content of %s: %w", relPath, err)
+	}
+	return readBlob(repo, entry.Hash)
+}
+
+// readBlob returns the content of the blob object
+func readBlob(repo *gog.Repository, hash plumbing.Hash) ([]byte, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// writeBlob writes the content as a blob object, returns its hash
+func writeBlob(repo *gog.Repository, content []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(content); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// fileBlobHash returns the git blob hash of the file content, as computed by `git hash-object`
+func fileBlobHash(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content).String(), nil
 }
 
 func (g *git) PathToRoot() string {
 	return g.pathToRoot
 }
 
-func (g *git) constructRepoRootFolderURL() error {
+func (g *git) constructRepoRootFolderURL(gcfg *GitConfig) error {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
+	remoteName := gcfg.Remote
+	if remoteName == "" {
+		remoteName = defaultRemoteName
+	}
+
 	// Get remote URL
-	remote, err := g.repo.Remote("origin")
+	remote, err := g.repo.Remote(remoteName)
 	if err != nil {
-		return fmt.Errorf("failed to get origin remote: %w", err)
+		return fmt.Errorf("failed to get %s remote: %w", remoteName, err)
 	}
 
 	urls := remote.Config().URLs
 	if len(urls) == 0 {
-		return fmt.Errorf("no URLs found for origin remote")
+		return fmt.Errorf("no URLs found for %s remote", remoteName)
+	}
+	remoteURL, withCredentials, err := normalizeRemoteURL(urls[0])
+	if err != nil {
+		return err
+	}
+	if withCredentials {
+		Log("Warning: credentials are stripped from the remote URL", "remote", remoteName, "url", remoteURL)
 	}
-	remoteURL := urls[0]
 
 	// Determine which branch to use
-	var commitRef string
-
-	// Check if main branch exists
-	_, err = g.repo.Reference("refs/heads/main", false)
-	if err == nil {
-		// Main branch exists
-		commitRef = "main"
-	} else {
-		// Check if master branch exists
-		_, err = g.repo.Reference("refs/heads/master", false)
-		if err == nil {
-			// Master branch exists
-			commitRef = "master"
-		} else {
-			return fmt.Errorf("neither main nor master branch exists")
+	commitRef := gcfg.Ref
+	if gcfg.Permalinks {
+		if commitRef != "" {
+			return fmt.Errorf("commit ref can not be specified for permalinks")
+		}
+		commitRef = g.commit.Hash.String()
+	}
+	if commitRef == "" {
+		commitRef, err = g.resolveCommitRef(remoteName)
+		if err != nil {
+			return err
 		}
 	}
+	Verbose("Commit ref", "ref", commitRef, "remote", remoteName)
 
 	// Detect provider and construct URL
-	switch {
-	case strings.Contains(remoteURL, "github.com"):
-		g.repoRootFolderURL = fmt.Sprintf("%s/blob/%s", remoteURL, commitRef)
-	case strings.Contains(remoteURL, "gitlab.com"):
-		g.repoRootFolderURL = fmt.Sprintf("%s/-/blob/%s", remoteURL, commitRef)
-	default:
-		return fmt.Errorf("unsupported git provider: %s", remoteURL)
+	tmpl, err := resolveURLTemplate(remoteURL, gcfg.URLTemplates, gcfg.Permalinks)
+	if err != nil {
+		return err
 	}
+	g.urlTemplate = expandRepoURLTemplate(tmpl, remoteURL, commitRef)
+	g.repoRootFolderURL = repoRootFolderURL(g.urlTemplate)
 
 	return nil
 }
 
+// resolveCommitRef returns the first of:
+//   - Default branch of the remote (refs/remotes/<remote>/HEAD)
+//   - Local main or master branch
+//   - Remote-tracking main or master branch
+//   - Branch from CI environment variables, ref. ciRefEnvVars
+func (g *git) resolveCommitRef(remoteName string) (string, error) {
+	remotePrefix := "refs/remotes/" + remoteName + "/"
+
+	if ref, err := g.repo.Reference(plumbing.ReferenceName(remotePrefix+"HEAD"), false); err == nil && ref.Type() == plumbing.SymbolicReference {
+		if branch, ok := strings.CutPrefix(ref.Target().String(), remotePrefix); ok {
+			return branch, nil
+		}
+	}
+
+	for _, prefix := range []string{"refs/heads/", remotePrefix} {
+		for _, branch := range []string{"main", "master"} {
+			if _, err := g.repo.Reference(plumbing.ReferenceName(prefix+branch), false); err == nil {
+				return branch, nil
+			}
+		}
+	}
+
+	for _, name := range ciRefEnvVars {
+		if branch := strings.TrimPrefix(os.Getenv(name), "refs/heads/"); branch != "" {
+			Verbose("Commit ref is taken from the environment", "var", name)
+			return branch, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to determine the commit ref: neither %sHEAD nor main/master branches exist, use --ref to specify the ref", remotePrefix)
+}
+
 func (g *git) RepoRootFolderURL() string {
 	return g.repoRootFolderURL
 }
+
+func (g *git) CoverageURLTemplate() string {
+	return g.urlTemplate
+}
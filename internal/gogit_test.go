@@ -5,12 +5,14 @@ package internal_test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	cfg "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/require"
 	"github.com/voedger/reqmd/internal"
@@ -149,3 +151,262 @@ func Test_IGit(t *testing.T) {
 	repoURL := igit.RepoRootFolderURL()
 	require.Contains(t, repoURL, "https://github.com/voedger/example/blob/", "Repo URL should be properly constructed")
 }
+
+// Checks that URLs of self-hosted repositories are constructed using configured templates
+func Test_IGit_URLTemplates(t *testing.T) {
+	testFolder := ".testdata/Test_IGit_URLTemplates"
+	createTestGitRepo(t, testFolder, "origin", "https://git.example.com/team/example")
+
+	// Unknown host
+	_, err := internal.NewGitVCS(testFolder)
+	require.ErrorContains(t, err, "unsupported git provider")
+
+	// Host is mapped to a built-in provider
+	igit, err := internal.NewGitVCSEx(testFolder, &internal.GitConfig{
+		URLTemplates: map[string]string{"git.example.com": "gitea"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://git.example.com/team/example/src/branch/master", igit.RepoRootFolderURL())
+	require.Equal(t, "https://git.example.com/team/example/src/branch/master/{path}#L{line}", igit.CoverageURLTemplate())
+
+	// Permalinks
+	repo, err := gogit.PlainOpen(testFolder)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	igit, err = internal.NewGitVCSEx(testFolder, &internal.GitConfig{
+		URLTemplates: map[string]string{"git.example.com": "gitea"},
+		Permalinks:   true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://git.example.com/team/example/src/commit/"+head.Hash().String(), igit.RepoRootFolderURL())
+
+	// Host is mapped to a custom template
+	igit, err = internal.NewGitVCSEx(testFolder, &internal.GitConfig{
+		URLTemplates: map[string]string{"git.example.com": "{base}/files/{ref}/{path}?line={line}"},
+	})
+	require.NoError(t, err)
+	file := internal.FileStructure{RelativePath: "1.txt", CoverageURLTemplate: igit.CoverageURLTemplate()}
+	require.Equal(t, "https://git.example.com/team/example/files/master/1.txt?line=3", file.CoverageURL(3))
+}
+
+// Checks that the commit ref is resolved from the remote HEAD, branches and CI environment variables
+func Test_IGit_CommitRef(t *testing.T) {
+	testFolder := ".testdata/Test_IGit_CommitRef"
+	repo := createTestGitRepo(t, testFolder, "upstream", "git@github.com:voedger/example.git")
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// The environment of the CI that runs the test shall not be used
+	for _, name := range []string{"CI_DEFAULT_BRANCH", "CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "GITHUB_BASE_REF",
+		"BITBUCKET_PR_DESTINATION_BRANCH", "SYSTEM_PULLREQUEST_TARGETBRANCH", "GITHUB_REF_NAME", "CI_COMMIT_BRANCH",
+		"BITBUCKET_BRANCH", "BUILD_SOURCEBRANCH"} {
+		t.Setenv(name, "")
+	}
+
+	newGitVCS := func(gcfg internal.GitConfig) (internal.IVCS, error) {
+		if gcfg.Remote == "" {
+			gcfg.Remote = "upstream"
+		}
+		return internal.NewGitVCSEx(testFolder, &gcfg)
+	}
+
+	// Remote is not found
+	_, err = internal.NewGitVCS(testFolder)
+	require.ErrorContains(t, err, "origin remote")
+
+	// Local master branch
+	igit, err := newGitVCS(internal.GitConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/master", igit.RepoRootFolderURL())
+
+	// Explicit ref
+	igit, err = newGitVCS(internal.GitConfig{Ref: "release/1.0"})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/release/1.0", igit.RepoRootFolderURL())
+
+	// Default branch of the remote takes precedence over local branches
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/upstream/develop", head.Hash())))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference("refs/remotes/upstream/HEAD", "refs/remotes/upstream/develop")))
+	igit, err = newGitVCS(internal.GitConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/develop", igit.RepoRootFolderURL())
+
+	// Detached HEAD without local branches (CI checkout)
+	require.NoError(t, repo.Storer.RemoveReference("refs/remotes/upstream/HEAD"))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, head.Hash())))
+	require.NoError(t, repo.Storer.RemoveReference(head.Name()))
+	_, err = newGitVCS(internal.GitConfig{})
+	require.ErrorContains(t, err, "--ref")
+
+	// Remote-tracking branch
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/upstream/main", head.Hash())))
+	igit, err = newGitVCS(internal.GitConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/main", igit.RepoRootFolderURL())
+	require.NoError(t, repo.Storer.RemoveReference("refs/remotes/upstream/main"))
+
+	// CI environment variables
+	t.Setenv("BUILD_SOURCEBRANCH", "refs/heads/feature")
+	t.Setenv("CI_DEFAULT_BRANCH", "trunk")
+	igit, err = newGitVCS(internal.GitConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/trunk", igit.RepoRootFolderURL())
+
+	t.Setenv("CI_DEFAULT_BRANCH", "")
+	igit, err = newGitVCS(internal.GitConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/example/blob/feature", igit.RepoRootFolderURL())
+}
+
+// createTestGitRepo creates a git repo with one committed file and the given remote
+func createTestGitRepo(t *testing.T, testFolder, remoteName, remoteURL string) *gogit.Repository {
+	_ = os.RemoveAll(testFolder)
+	require.NoError(t, os.MkdirAll(testFolder, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(testFolder, "1.txt"), []byte("1.txt content"), 0644))
+
+	repo, err := gogit.PlainInit(testFolder, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(".")
+	require.NoError(t, err)
+	_, err = wt.Commit("initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&cfg.RemoteConfig{
+		Name: remoteName,
+		URLs: []string{remoteURL},
+	})
+	require.NoError(t, err)
+	return repo
+}
+
+// Checks that files of submodules and linked worktrees are attributed to the innermost repository
+// Uses git CLI to create a superproject with a submodule and a linked worktree
+func Test_IGit_SubmoduleAndWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found")
+	}
+
+	testFolder, err := filepath.Abs(".testdata/Test_IGit_SubmoduleAndWorktree")
+	require.NoError(t, err)
+	_ = os.RemoveAll(testFolder)
+	svcFolder := filepath.Join(testFolder, "svc")
+	superFolder := filepath.Join(testFolder, "platform")
+	worktreeFolder := filepath.Join(testFolder, "platform-feature")
+
+	runGit := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "protocol.file.allow=always"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	writeFile := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Service repository
+	writeFile(filepath.Join(svcFolder, "svc.go"), "package svc\n\n// [~platform/REQ001~impl]\nfunc Handler() {}\n")
+	runGit(svcFolder, "init", "-b", "main")
+	runGit(svcFolder, "add", ".")
+	runGit(svcFolder, "commit", "-m", "initial commit")
+
+	// Superproject with the service as a submodule
+	writeFile(filepath.Join(superFolder, "req.md"), "---\nreqmd.package: platform\n---\n\n- `~REQ001~`\n")
+	runGit(superFolder, "init", "-b", "main")
+	runGit(superFolder, "remote", "add", "origin", "git@github.com:voedger/platform.git")
+	runGit(superFolder, "submodule", "add", svcFolder, "services/svc")
+	runGit(superFolder, "add", ".")
+	runGit(superFolder, "commit", "-m", "initial commit")
+	runGit(filepath.Join(superFolder, "services", "svc"), "remote", "set-url", "origin", "https://github.com/voedger/svc")
+
+	// Files of the submodule
+	res, err := internal.NewScanner(&internal.ScannerConfig{}).Scan(t.Context(), []string{superFolder})
+	require.NoError(t, err)
+	require.Empty(t, res.ProcessingErrors)
+	urls := make(map[string]string)
+	for _, f := range res.Files {
+		urls[f.RelativePath] = f.CoverageURL(1)
+	}
+	require.Equal(t, map[string]string{
+		"req.md": "https://github.com/voedger/platform/blob/main/req.md#L1",
+		"svc.go": "https://github.com/voedger/svc/blob/main/svc.go#L1",
+	}, urls)
+
+	// Submodule is opened directly
+	igit, err := internal.NewGitVCS(filepath.Join(superFolder, "services", "svc"))
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/voedger/svc/blob/main", igit.RepoRootFolderURL())
+
+	// Linked worktree
+	runGit(superFolder, "worktree", "add", "-b", "feature", worktreeFolder)
+	igit, err = internal.NewGitVCS(worktreeFolder)
+	require.NoError(t, err)
+	require.Equal(t, filepath.ToSlash(worktreeFolder), igit.PathToRoot())
+	require.Equal(t, "https://github.com/voedger/platform/blob/main", igit.RepoRootFolderURL())
+	relPath, hash, err := igit.FileHash(filepath.Join(worktreeFolder, "req.md"))
+	require.NoError(t, err)
+	require.Equal(t, "req.md", relPath)
+	require.NotEmpty(t, hash)
+}
+
+// Checks that untracked and staged files are processed when requested
+func Test_IGit_UntrackedAndStaged(t *testing.T) {
+	testFolder := ".testdata/Test_IGit_UntrackedAndStaged"
+	repo := createTestGitRepo(t, testFolder, "origin", "https://github.com/voedger/example")
+
+	writeFile := func(name, content string) string {
+		path, err := filepath.Abs(filepath.Join(testFolder, name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	committed, err := filepath.Abs(filepath.Join(testFolder, "1.txt"))
+	require.NoError(t, err)
+	untracked := writeFile("2.txt", "2.txt content")
+	stagedFile := writeFile("3.txt", "3.txt content")
+	ignored := writeFile("4.log", "4.log content")
+	writeFile(".gitignore", "*.log\n")
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	stagedHash, err := wt.Add("3.txt")
+	require.NoError(t, err)
+
+	newGitVCS := func(gcfg internal.GitConfig) internal.IVCS {
+		igit, err := internal.NewGitVCSEx(testFolder, &gcfg)
+		require.NoError(t, err)
+		return igit
+	}
+
+	// Committed files only
+	igit := newGitVCS(internal.GitConfig{})
+	_, _, err = igit.FileHash(committed)
+	require.NoError(t, err)
+	_, _, err = igit.FileHash(untracked)
+	require.ErrorContains(t, err, "not committed")
+	_, _, err = igit.FileHash(stagedFile)
+	require.ErrorContains(t, err, "not committed")
+
+	// Staged files
+	igit = newGitVCS(internal.GitConfig{Staged: true})
+	_, _, err = igit.FileHash(committed)
+	require.NoError(t, err)
+	_, hash, err := igit.FileHash(stagedFile)
+	require.NoError(t, err)
+	require.Equal(t, stagedHash.String(), hash)
+	_, _, err = igit.FileHash(untracked)
+	require.ErrorContains(t, err, "not staged")
+
+	// Untracked files
+	igit = newGitVCS(internal.GitConfig{IncludeUntracked: true})
+	relPath, hash, err := igit.FileHash(untracked)
+	require.NoError(t, err)
+	require.Equal(t, "2.txt", relPath)
+	require.Equal(t, plumbing.ComputeHash(plumbing.BlobObject, []byte("2.txt content")).String(), hash)
+	_, _, err = igit.FileHash(ignored)
+	require.ErrorContains(t, err, "ignored")
+}
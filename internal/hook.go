@@ -0,0 +1,260 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gog "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+const (
+	preCommitHookName = "pre-commit"
+	// Marks hook scripts that are written by reqmd and can be overwritten
+	hookScriptMarker = "# Installed by reqmd install-hook"
+)
+
+// NewStagingApplier returns IApplier for the pre-commit hook, actions are computed from the staged content:
+//   - Actions are applied to the staged content of markdown files, updated blobs are written to the git index
+//   - Files whose working tree content matches the index are updated in the working tree by applier as well,
+//     unstaged changes of other files are kept, so `git diff` shows updated annotations as removed until the next trace
+//   - The temporary index that git passes to hooks using GIT_INDEX_FILE is updated, ref. gitIndexFile()
+func NewStagingApplier(applier IApplier) IApplier {
+	return &stagingApplier{applier: applier}
+}
+
+type stagingApplier struct {
+	applier IApplier
+}
+
+// stagedRepo is a repository with markdown files to be staged
+type stagedRepo struct {
+	root  string
+	repo  *gog.Repository
+	index *index.Index
+	files []*stagedFile
+}
+
+// stagedFile is a markdown file whose staged content is updated
+type stagedFile struct {
+	path    FilePath
+	relPath string
+	entry   *index.Entry
+	updated []byte
+	inWt    bool // the working tree content matches the index and is updated as well
+}
+
+func (a *stagingApplier) Apply(ctx context.Context, ar *AnalyzerResult) error {
+	var repos []*stagedRepo
+	reposByRoot := make(map[string]*stagedRepo)
+	var notStaged []string
+	wtActions := make(map[FilePath][]MdAction)
+	for _, path := range sortedMdActionPaths(ar.MdActions) {
+		if len(ar.MdActions[path]) == 0 {
+			continue
+		}
+		root, err := findGitRoot(path)
+		if err != nil {
+			return err
+		}
+		r, ok := reposByRoot[root]
+		if !ok {
+			repo, err := openGitRepo(root)
+			if err != nil {
+				return err
+			}
+			idx, err := readGitIndex(repo, root)
+			if err != nil {
+				return err
+			}
+			r = &stagedRepo{root: root, repo: repo, index: idx}
+			reposByRoot[root] = r
+			repos = append(repos, r)
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		entry, err := r.index.Entry(relPath)
+		if err != nil {
+			if errors.Is(err, index.ErrEntryNotFound) {
+				notStaged = append(notStaged, path)
+				continue
+			}
+			return err
+		}
+		staged, err := readBlob(r.repo, entry.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to read staged content of %s: %w", relPath, err)
+		}
+		updated, err := applyMdActions(path, staged, ar.MdActions[path])
+		if err != nil {
+			return err
+		}
+		hash, err := fileBlobHash(path)
+		if err != nil {
+			return err
+		}
+		f := &stagedFile{path: path, relPath: relPath, entry: entry, updated: updated, inWt: hash == entry.Hash.String()}
+		if f.inWt {
+			wtActions[path] = ar.MdActions[path]
+		} else {
+			Verbose("Working tree is not updated, it has unstaged changes", "path", relPath)
+		}
+		r.files = append(r.files, f)
+	}
+
+	if len(notStaged) > 0 {
+		return fmt.Errorf("reqmd: files are not staged, stage them before commit:\n\t%s", strings.Join(notStaged, "\n\t"))
+	}
+
+	// Applied changes are staged regardless of ctx
+	if len(wtActions) > 0 {
+		if err := a.applier.Apply(ctx, &AnalyzerResult{MdActions: wtActions}); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range repos {
+		if err := r.stage(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stage writes updated blobs and the index of the repository
+func (r *stagedRepo) stage() error {
+	for _, f := range r.files {
+		hash, err := writeBlob(r.repo, f.updated)
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", f.relPath, err)
+		}
+		f.entry.Hash = hash
+		f.entry.Size = uint32(len(f.updated))
+		if f.inWt {
+			if info, err := os.Stat(f.path); err == nil {
+				f.entry.ModifiedAt = info.ModTime()
+			}
+		}
+		Verbose("Staged", "path", f.relPath)
+	}
+	if err := writeGitIndex(r.repo, r.root, r.index); err != nil {
+		return fmt.Errorf("failed to write git index: %w", err)
+	}
+	return nil
+}
+
+// InstallHook writes the pre-commit hook script that runs `reqmd hook pre-commit <paths>` to the repository that contains repoPath.
+// Existing hook scripts that are not written by reqmd are overwritten only if force is true.
+// Returns the path to the written script.
+func InstallHook(repoPath string, paths []string, force bool) (string, error) {
+	root, err := findGitRoot(repoPath)
+	if err != nil {
+		return "", err
+	}
+	hooksDir, err := gitHooksDir(root)
+	if err != nil {
+		return "", err
+	}
+	hookPath := filepath.Join(hooksDir, preCommitHookName)
+
+	if existing, err := os.ReadFile(hookPath); err == nil {
+		if !force && !strings.Contains(string(existing), hookScriptMarker) {
+			return "", fmt.Errorf("%s already exists, use --force to overwrite it", hookPath)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = "'" + strings.ReplaceAll(filepath.ToSlash(p), "'", `'\''`) + "'"
+	}
+	script := "#!/bin/sh\n" +
+		hookScriptMarker + "\n" +
+		"exec reqmd hook " + preCommitHookName + " " + strings.Join(quoted, " ") + "\n"
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+		return "", err
+	}
+	// WriteFile does not change the mode of existing files
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return "", err
+	}
+	return hookPath, nil
+}
+
+// gitHooksDir returns the hooks folder of the repository:
+//   - core.hooksPath, relative to the root of the repository
+//   - hooks folder of the common git dir, so linked worktrees share hooks with the main repository
+func gitHooksDir(root string) (string, error) {
+	repo, err := openGitRepo(root)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", err
+	}
+	if hooksPath := cfg.Raw.Section("core").Option("hooksPath"); hooksPath != "" {
+		if !filepath.IsAbs(hooksPath) {
+			hooksPath = filepath.Join(root, hooksPath)
+		}
+		return hooksPath, nil
+	}
+
+	gitDir, err := resolveGitDir(root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "hooks"), nil
+}
+
+// resolveGitDir returns the common git dir of the repository.
+// .git is a folder for regular repositories, for linked worktrees and submodules it is a file that refers to the git dir
+func resolveGitDir(root string) (string, error) {
+	gitDir := filepath.Join(root, gitFolderName)
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return gitDir, nil
+	}
+
+	content, err := os.ReadFile(gitDir)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid %s file: %s", gitFolderName, gitDir)
+	}
+	gitDir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+
+	// Linked worktrees refer to the main git dir using commondir
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		dir := strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitDir, dir)
+		}
+		return filepath.Clean(dir), nil
+	}
+	return gitDir, nil
+}
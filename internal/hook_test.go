@@ -0,0 +1,139 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newHookTestRepo creates a git repository with committed req.md using git CLI
+func newHookTestRepo(t *testing.T) (dir string, git func(args ...string) string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found")
+	}
+	dir = t.TempDir()
+	git = func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+	git("init", "-b", "main")
+	git("remote", "add", "origin", "https://github.com/voedger/example")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "req.md"), []byte("---\nreqmd.package: pkg\n---\n\n- `~REQ001~`\n"), 0644))
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+	return dir, git
+}
+
+func runPreCommitHook(t *testing.T, dir string) error {
+	scfg := &ScannerConfig{GitConfig: &GitConfig{StagedContent: true}}
+	applier := NewStagingApplier(NewApplier(&ApplierConfig{}))
+	return NewTracer(NewScanner(scfg), NewAnalyzer(), applier, NewVerifier(scfg, NewAnalyzer), []string{dir}).Trace(t.Context())
+}
+
+func TestStagingApplier_PreCommit(t *testing.T) {
+	dir, git := newHookTestRepo(t)
+
+	// Staged content covers REQ001, the working tree content does not
+	implPath := filepath.Join(dir, "impl.go")
+	require.NoError(t, os.WriteFile(implPath, []byte("package pkg\n\n// [~pkg/REQ001~impl]\nfunc Impl() {}\n"), 0644))
+	git("add", "impl.go")
+	require.NoError(t, os.WriteFile(implPath, []byte("package pkg\n\nfunc Impl() {}\n"), 0644))
+
+	require.NoError(t, runPreCommitHook(t, dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, "req.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "[impl.go:3:impl](https://github.com/voedger/example/blob/main/impl.go#L3)")
+
+	// Updated req.md is staged, impl.go is still partially staged
+	require.Equal(t, "impl.go\nreq.md\n", git("diff", "--cached", "--name-only"))
+	require.Equal(t, "impl.go\n", git("diff", "--name-only"))
+}
+
+func TestStagingApplier_PartiallyStaged(t *testing.T) {
+	dir, git := newHookTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), []byte("package pkg\n\n// [~pkg/REQ001~impl]\n"), 0644))
+	git("add", "impl.go")
+
+	// req.md has unstaged changes
+	mdPath := filepath.Join(dir, "req.md")
+	original := "---\nreqmd.package: pkg\n---\n\n- `~REQ001~`\n\nUnstaged line\n"
+	require.NoError(t, os.WriteFile(mdPath, []byte(original), 0644))
+
+	require.NoError(t, runPreCommitHook(t, dir))
+
+	// The staged content is updated, the working tree is not
+	require.Equal(t, "---\nreqmd.package: pkg\n---\n\n- `~REQ001~`covrd[^1]✅\n\n"+
+		"[^1]: `[~pkg/REQ001~impl]` [impl.go:3:impl](https://github.com/voedger/example/blob/main/impl.go#L3)\n",
+		git("show", ":req.md"))
+	content, err := os.ReadFile(mdPath)
+	require.NoError(t, err)
+	require.Equal(t, original, string(content))
+}
+
+func TestStagingApplier_IndexFile(t *testing.T) {
+	dir, git := newHookTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), []byte("package pkg\n\n// [~pkg/REQ001~impl]\n"), 0644))
+
+	// Temporary index like the one of `git commit -a`, git runs hooks in the root of the working tree
+	t.Setenv("GIT_INDEX_FILE", filepath.Join(dir, ".git", "next-index-1.lock"))
+	git("read-tree", "HEAD")
+	git("add", "impl.go")
+	t.Chdir(dir)
+
+	require.NoError(t, runPreCommitHook(t, dir))
+
+	annotated := "---\nreqmd.package: pkg\n---\n\n- `~REQ001~`covrd[^1]✅\n\n" +
+		"[^1]: `[~pkg/REQ001~impl]` [impl.go:3:impl](https://github.com/voedger/example/blob/main/impl.go#L3)\n"
+	require.Equal(t, annotated, git("show", ":req.md"))
+	content, err := os.ReadFile(filepath.Join(dir, "req.md"))
+	require.NoError(t, err)
+	require.Equal(t, annotated, string(content))
+
+	// The regular index is not changed
+	require.NoError(t, os.Unsetenv("GIT_INDEX_FILE"))
+	require.Empty(t, git("diff", "--cached", "--name-only"))
+}
+
+func TestInstallHook(t *testing.T) {
+	dir, git := newHookTestRepo(t)
+	hookPath := filepath.Join(dir, ".git", "hooks", preCommitHookName)
+
+	path, err := InstallHook(dir, []string{"docs", "src dir"}, false)
+	require.NoError(t, err)
+	require.Equal(t, hookPath, path)
+	content, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(content), "exec reqmd hook pre-commit 'docs' 'src dir'\n"))
+	info, err := os.Stat(hookPath)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&0100, "hook shall be executable")
+
+	// Hook written by reqmd is overwritten
+	_, err = InstallHook(dir, []string{"."}, false)
+	require.NoError(t, err)
+
+	// Foreign hook is kept unless forced
+	require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\nexit 0\n"), 0755))
+	_, err = InstallHook(dir, []string{"."}, false)
+	require.ErrorContains(t, err, "--force")
+	_, err = InstallHook(dir, []string{"."}, true)
+	require.NoError(t, err)
+
+	// core.hooksPath
+	git("config", "core.hooksPath", ".githooks")
+	path, err = InstallHook(dir, []string{"."}, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, ".githooks", preCommitHookName), path)
+}
@@ -3,16 +3,20 @@
 
 package internal
 
+import "context"
+
 // ITracer defines the high-level interface for tracing workflow.
 // It orchestrates scanning, analyzing, and applying changes.
 type ITracer interface {
-	Trace() error
+	// Trace stops when ctx is done, files are not changed after that
+	Trace(ctx context.Context) error
 }
 
 // IScanner is responsible for scanning file paths and parsing them into FileStructures.
 type IScanner interface {
 	// ScanMultiPath scans multiple paths that can each contain both markdown and source files
-	Scan(paths []string) (*ScannerResult, error)
+	// Scanning stops when ctx is done, ctx.Err() is returned then
+	Scan(ctx context.Context, paths []string) (*ScannerResult, error)
 }
 
 // IAnalyzer checks for semantic issues (e.g., unique RequirementIds) and generates Actions.
@@ -22,7 +26,13 @@ type IAnalyzer interface {
 
 // IApplier applies the Actions (file updates, footnote generation, etc.).
 type IApplier interface {
-	Apply(*AnalyzerResult) error
+	// Files are not changed if ctx is done before changes are committed
+	Apply(ctx context.Context, ar *AnalyzerResult) error
+}
+
+// IVerifier checks that applied changes are stable, i.e. repeated analysis of the changed files produces no Actions.
+type IVerifier interface {
+	Verify(files []FileStructure, applied *AnalyzerResult) error
 }
 
 type IVCS interface {
@@ -30,4 +40,8 @@ type IVCS interface {
 	PathToRoot() string // TODO: do we need this?
 	FileHash(absoluteFilePath string) (relPath, hash string, err error)
 	RepoRootFolderURL() string
+	// URL template with {path} and {line} placeholders, ref. FileStructure.CoverageURLTemplate
+	CoverageURLTemplate() string
+	// Content of the file to be parsed: the staged content if GitConfig.StagedContent is set, the working tree content otherwise
+	ReadFile(absoluteFilePath string) ([]byte, error)
 }
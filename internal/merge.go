@@ -0,0 +1,413 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultConflictMarkerSize = 7
+
+// MergeConfig configures MergeMarkdown
+type MergeConfig struct {
+	ConflictMarkerSize int // defaultConflictMarkerSize if 0
+}
+
+// MergeMarkdown merges markdown files for the git merge driver:
+//   - Coverage footnotes are removed and RequirementSites are stripped to bare labels in all versions
+//   - Summary blocks are stripped down to their begin markers in all versions, the next trace fills them in
+//   - The prose is merged line by line using the common ancestor (diff3)
+//   - Site annotations and footnotes are regenerated from the 3-way merge of the Coverers, ref. mergeCoverers()
+//   - Footnote ids of ours are kept, ids of theirs are kept unless they clash, then they are renumbered
+//
+// path is used to report errors only. conflicts is the number of prose conflicts that are written using conflict markers.
+func MergeMarkdown(mcfg *MergeConfig, path string, base, ours, theirs []byte) (merged []byte, conflicts int, err error) {
+	// Parse as markdown regardless of the name of the temporary file
+	if !strings.EqualFold(filepath.Ext(path), markdownExtension) {
+		path += markdownExtension
+	}
+
+	o, a, b, err := parseMergeVersions(path, base, ours, theirs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	markerSize := mcfg.ConflictMarkerSize
+	if markerSize == 0 {
+		markerSize = defaultConflictMarkerSize
+	}
+	lines, conflicts := merge3(o.prose, a.prose, b.prose, markerSize)
+
+	merged, err = annotateMerged(path, lines, o, a, b)
+	return merged, conflicts, err
+}
+
+func parseMergeVersions(path string, base, ours, theirs []byte) (o, a, b *mergeVersion, err error) {
+	if o, err = parseMergeVersion(path, base); err != nil {
+		return nil, nil, nil, err
+	}
+	if a, err = parseMergeVersion(path, ours); err != nil {
+		return nil, nil, nil, err
+	}
+	if b, err = parseMergeVersion(path, theirs); err != nil {
+		return nil, nil, nil, err
+	}
+	return o, a, b, nil
+}
+
+// annotateMerged regenerates annotations of the merged prose, line endings of ours are used
+func annotateMerged(path string, lines []string, o, a, b *mergeVersion) ([]byte, error) {
+	// Parse the merged prose to find RequirementSites and the PackageId
+	structure, _, err := parseFileEx(&ScannerContext{}, path, bytes.NewReader(joinLinesPreserveEndings(lines, false)))
+	if err != nil {
+		return nil, err
+	}
+
+	if structure.PackageId != "" {
+		lines = regenerateAnnotations(lines, structure, o, a, b)
+	}
+
+	if a.eol {
+		lines = append(lines, "")
+	}
+	return joinLinesPreserveEndings(lines, a.hasCRLF), nil
+}
+
+// mergeVersion is a version of the markdown file with stripped coverage annotations
+type mergeVersion struct {
+	prose      []string                               // lines without coverage footnotes and with bare RequirementSites
+	ids        map[RequirementName]CoverageFootnoteId // for annotated RequirementSites
+	coverers   map[RequirementName]map[string]string  // CoverageLabel -> CoverageURL
+	footnoteId map[CoverageFootnoteId]bool            // ids of all coverage footnotes
+	hasCRLF    bool
+	eol        bool // content ends with a line ending
+}
+
+func parseMergeVersion(path string, content []byte) (*mergeVersion, error) {
+	v := &mergeVersion{
+		ids:        make(map[RequirementName]CoverageFootnoteId),
+		coverers:   make(map[RequirementName]map[string]string),
+		footnoteId: make(map[CoverageFootnoteId]bool),
+	}
+	lines, hasCRLF := splitLinesPreserveEndings(content)
+	v.hasCRLF = hasCRLF
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		v.eol = true
+		lines = lines[:len(lines)-1]
+	}
+
+	structure, _, err := parseFileEx(&ScannerContext{}, path, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// Summary blocks are stripped down to their begin markers, so they never conflict, the next trace fills them in
+	summaryLines := make(map[int]bool)
+	for _, block := range structure.SummaryBlocks {
+		for lineNum := block.Line + 1; lineNum <= block.EndLine; lineNum++ {
+			summaryLines[lineNum] = true
+		}
+	}
+
+	if structure.PackageId == "" {
+		// Not a requirements file, merged as is
+		for i, line := range lines {
+			if !summaryLines[i+1] {
+				v.prose = append(v.prose, line)
+			}
+		}
+		return v, nil
+	}
+
+	footnotes := make(map[CoverageFootnoteId]*CoverageFootnote)
+	footnoteLines := make(map[int]bool)
+	for i := range structure.CoverageFootnotes {
+		f := &structure.CoverageFootnotes[i]
+		// Footnotes without the hint are not generated by reqmd
+		if f.PackageId == "" {
+			continue
+		}
+		footnotes[f.CoverageFootnoteId] = f
+		footnoteLines[f.Line] = true
+		v.footnoteId[f.CoverageFootnoteId] = true
+	}
+
+	siteLines := make(map[int]bool)
+	for _, site := range structure.Requirements {
+		siteLines[site.Line] = true
+		if !site.HasAnnotationRef {
+			continue
+		}
+		v.ids[site.RequirementName] = site.CoverageFootnoteId
+		coverers := make(map[string]string)
+		if f, ok := footnotes[site.CoverageFootnoteId]; ok {
+			for _, c := range f.Coverers {
+				coverers[c.CoverageLabel] = c.CoverageURL
+			}
+		}
+		v.coverers[site.RequirementName] = coverers
+	}
+
+	for i, line := range lines {
+		lineNum := i + 1
+		if footnoteLines[lineNum] || summaryLines[lineNum] {
+			continue
+		}
+		if siteLines[lineNum] {
+			line = RequirementSiteRegex.ReplaceAllString(line, "`~$1~`")
+		}
+		v.prose = append(v.prose, line)
+	}
+	// Separator of the footnotes
+	for len(v.prose) > 0 && strings.TrimSpace(v.prose[len(v.prose)-1]) == "" {
+		v.prose = v.prose[:len(v.prose)-1]
+	}
+	return v, nil
+}
+
+// regenerateAnnotations annotates RequirementSites of the merged prose and appends coverage footnotes.
+// RequirementSites that are bare in all versions are kept bare.
+func regenerateAnnotations(lines []string, structure *FileStructure, o, a, b *mergeVersion) []string {
+	// Ids that can not be used by coverage footnotes
+	taken := make(map[CoverageFootnoteId]bool)
+	for _, f := range structure.CoverageFootnotes {
+		taken[f.CoverageFootnoteId] = true
+	}
+	maxId := 0
+	for _, v := range []*mergeVersion{o, a, b} {
+		for id := range v.footnoteId {
+			if n, err := strconv.Atoi(string(id)); err == nil {
+				maxId = max(maxId, n)
+			}
+		}
+	}
+	for id := range taken {
+		if n, err := strconv.Atoi(string(id)); err == nil {
+			maxId = max(maxId, n)
+		}
+	}
+
+	// Sites in the order of appearance, ids of ours are assigned first.
+	// Footnotes are written in the order of assignment, so footnotes of ours keep their order
+	var names []RequirementName
+	seen := make(map[RequirementName]bool)
+	for _, site := range structure.Requirements {
+		if !seen[site.RequirementName] {
+			seen[site.RequirementName] = true
+			names = append(names, site.RequirementName)
+		}
+	}
+	ids := make(map[RequirementName]CoverageFootnoteId)
+	var assigned []RequirementName
+	for _, name := range names {
+		if id, ok := a.ids[name]; ok && !taken[id] {
+			ids[name] = id
+			taken[id] = true
+			assigned = append(assigned, name)
+		}
+	}
+	for _, name := range names {
+		if _, ok := ids[name]; ok {
+			continue
+		}
+		id, ok := b.ids[name]
+		if !ok {
+			if id, ok = o.ids[name]; !ok {
+				if _, annotated := a.ids[name]; !annotated {
+					// Bare in all versions
+					continue
+				}
+			}
+		}
+		if !ok || taken[id] {
+			maxId++
+			id = CoverageFootnoteId(strconv.Itoa(maxId))
+		}
+		ids[name] = id
+		taken[id] = true
+		assigned = append(assigned, name)
+	}
+
+	sites := make(map[RequirementName]string)
+	var footnotes []string
+	for _, name := range assigned {
+		id := ids[name]
+		cf := &CoverageFootnote{
+			PackageId:          structure.PackageId,
+			RequirementName:    name,
+			CoverageFootnoteId: id,
+			Coverers:           mergeCoverers(o.coverers[name], a.coverers[name], b.coverers[name]),
+		}
+		status := CoverageStatusWordUncvrd
+		if len(cf.Coverers) > 0 {
+			status = CoverageStatusWordCovrd
+		}
+		sites[name] = FormatRequirementSite(name, status, id)
+		footnotes = append(footnotes, FormatCoverageFootnote(cf))
+	}
+
+	res := make([]string, 0, len(lines)+len(footnotes)+1)
+	siteLines := make(map[int]bool)
+	for _, site := range structure.Requirements {
+		siteLines[site.Line] = true
+	}
+	for i, line := range lines {
+		if siteLines[i+1] {
+			line = RequirementSiteRegex.ReplaceAllStringFunc(line, func(m string) string {
+				name := RequirementName(RequirementSiteRegex.FindStringSubmatch(m)[1])
+				if site, ok := sites[name]; ok {
+					return site
+				}
+				return m
+			})
+		}
+		res = append(res, line)
+	}
+	if len(footnotes) > 0 {
+		if needFootnoteSeparator(res) {
+			res = append(res, "")
+		}
+		res = append(res, footnotes...)
+	}
+	return res
+}
+
+// mergeCoverers merges Coverers of a requirement: Coverers that are added by either side are added,
+// Coverers that are removed by either side are removed. CoverageURLs that are changed by theirs win if ours are not changed.
+func mergeCoverers(o, a, b map[string]string) []Coverer {
+	var res []Coverer
+	for label, urlA := range a {
+		urlB, inB := b[label]
+		urlO, inO := o[label]
+		switch {
+		case inB:
+			if inO && urlA == urlO {
+				urlA = urlB
+			}
+			res = append(res, Coverer{CoverageLabel: label, CoverageURL: urlA})
+		case !inO:
+			// Added by ours
+			res = append(res, Coverer{CoverageLabel: label, CoverageURL: urlA})
+		}
+	}
+	for label, urlB := range b {
+		_, inA := a[label]
+		_, inO := o[label]
+		if !inA && !inO {
+			// Added by theirs
+			res = append(res, Coverer{CoverageLabel: label, CoverageURL: urlB})
+		}
+	}
+	sortCoverers(res)
+	return res
+}
+
+// lineMatches returns indexes of lines of x that are equal to lines of o
+func lineMatches(o, x []string) map[int]int {
+	matches := make(map[int]int)
+	i, j := 0, 0
+	for _, d := range diffLines(o, x) {
+		switch d.op {
+		case diffOpEqual:
+			matches[i] = j
+			i++
+			j++
+		case diffOpDelete:
+			i++
+		case diffOpInsert:
+			j++
+		}
+	}
+	return matches
+}
+
+// merge3Region is a region of the common ancestor o and both sides: lines that are kept by both sides or a chunk between them
+type merge3Region struct {
+	stable                 bool // lines are kept by both sides, o, a and b are equal
+	oStart, aStart, bStart int  // indexes of the first lines of the region
+	o, a, b                []string
+}
+
+// merge returns lines of the side that changes the region, false if both sides change it differently
+func (r *merge3Region) merge() ([]string, bool) {
+	switch {
+	case slices.Equal(r.a, r.o):
+		return r.b, true
+	case slices.Equal(r.b, r.o), slices.Equal(r.a, r.b):
+		return r.a, true
+	}
+	return nil, false
+}
+
+// merge3Regions splits o, a and b into regions of lines that are kept by both sides and chunks between them
+func merge3Regions(o, a, b []string) []merge3Region {
+	var regions []merge3Region
+	ma, mb := lineMatches(o, a), lineMatches(o, b)
+	i, j, k := 0, 0, 0
+	for {
+		// Lines that are not changed by both sides
+		start := i
+		for i < len(o) {
+			ja, okA := ma[i]
+			kb, okB := mb[i]
+			if !okA || !okB || ja != j || kb != k {
+				break
+			}
+			i, j, k = i+1, j+1, k+1
+		}
+		if n := i - start; n > 0 {
+			regions = append(regions, merge3Region{
+				stable: true,
+				oStart: start, aStart: j - n, bStart: k - n,
+				o: o[start:i], a: a[j-n : j], b: b[k-n : k],
+			})
+		}
+
+		// Next line of o that is kept by both sides
+		m := i
+		for ; m < len(o); m++ {
+			_, okA := ma[m]
+			_, okB := mb[m]
+			if okA && okB {
+				break
+			}
+		}
+		ja, kb := len(a), len(b)
+		if m < len(o) {
+			ja, kb = ma[m], mb[m]
+		}
+
+		regions = append(regions, merge3Region{
+			oStart: i, aStart: j, bStart: k,
+			o: o[i:m], a: a[j:ja], b: b[k:kb],
+		})
+
+		if m >= len(o) {
+			return regions
+		}
+		i, j, k = m, ja, kb
+	}
+}
+
+// merge3 merges a and b with the common ancestor o line by line.
+// Chunks that are changed by both sides differently are written using conflict markers.
+func merge3(o, a, b []string, markerSize int) (merged []string, conflicts int) {
+	for _, r := range merge3Regions(o, a, b) {
+		if lines, ok := r.merge(); ok {
+			merged = append(merged, lines...)
+			continue
+		}
+		conflicts++
+		merged = append(merged, strings.Repeat("<", markerSize)+" ours")
+		merged = append(merged, r.a...)
+		merged = append(merged, strings.Repeat("=", markerSize))
+		merged = append(merged, r.b...)
+		merged = append(merged, strings.Repeat(">", markerSize)+" theirs")
+	}
+	return merged, conflicts
+}
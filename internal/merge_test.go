@@ -0,0 +1,140 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	o := []string{"a", "b", "c", "d"}
+
+	tests := []struct {
+		name      string
+		a, b      []string
+		want      []string
+		conflicts int
+	}{
+		{"unchanged", o, o, o, 0},
+		{"ours", []string{"a", "B", "c", "d"}, o, []string{"a", "B", "c", "d"}, 0},
+		{"theirs", o, []string{"a", "b", "c", "d", "e"}, []string{"a", "b", "c", "d", "e"}, 0},
+		{"both", []string{"A", "b", "c", "d"}, []string{"a", "b", "c"}, []string{"A", "b", "c"}, 0},
+		{"same change", []string{"a", "x", "c", "d"}, []string{"a", "x", "c", "d"}, []string{"a", "x", "c", "d"}, 0},
+		{
+			"conflict",
+			[]string{"a", "x", "c", "d"}, []string{"a", "y", "c", "d"},
+			[]string{"a", "<<<<<<< ours", "x", "=======", "y", ">>>>>>> theirs", "c", "d"}, 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := merge3(o, tt.a, tt.b, defaultConflictMarkerSize)
+			require.Equal(t, tt.want, merged)
+			require.Equal(t, tt.conflicts, conflicts)
+		})
+	}
+}
+
+func TestMergeMarkdown(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+	footnote := func(id, req string, coverers ...string) string {
+		return strings.TrimSpace("[^" + id + "]: `[~pkg/" + req + "~impl]` " + strings.Join(coverers, ", "))
+	}
+	c1 := "[a.go:1:impl](https://example.com/a.go#L1)"
+	c2 := "[b.go:2:impl](https://example.com/b.go#L2)"
+	c3 := "[c.go:3:impl](https://example.com/c.go#L3)"
+
+	base := header +
+		"- `~REQ001~`covrd[^1]✅\n" +
+		"\n" +
+		footnote("1", "REQ001", c1) + "\n"
+
+	// Ours adds a coverer to REQ001 and a new requirement REQ002
+	ours := header +
+		"- `~REQ001~`covrd[^1]✅\n" +
+		"- `~REQ002~`covrd[^2]✅\n" +
+		"\n" +
+		footnote("1", "REQ001", c1, c2) + "\n" +
+		footnote("2", "REQ002", c2) + "\n"
+
+	// Theirs removes the coverer of REQ001 and adds REQ003 using the same footnote id
+	theirs := header +
+		"Intro\n\n" +
+		"- `~REQ003~`covrd[^2]✅\n" +
+		"- `~REQ001~`uncvrd[^1]❓\n" +
+		"\n" +
+		footnote("1", "REQ001") + "\n" +
+		footnote("2", "REQ003", c3) + "\n"
+
+	merged, conflicts, err := MergeMarkdown(&MergeConfig{}, "req.md", []byte(base), []byte(ours), []byte(theirs))
+	require.NoError(t, err)
+	require.Zero(t, conflicts)
+
+	want := header +
+		"Intro\n\n" +
+		"- `~REQ003~`covrd[^3]✅\n" +
+		"- `~REQ001~`covrd[^1]✅\n" +
+		"- `~REQ002~`covrd[^2]✅\n" +
+		"\n" +
+		footnote("1", "REQ001", c2) + "\n" +
+		footnote("2", "REQ002", c2) + "\n" +
+		footnote("3", "REQ003", c3) + "\n"
+	require.Equal(t, want, string(merged))
+
+	t.Run("prose conflict", func(t *testing.T) {
+		ours := strings.Replace(base, "- `~REQ001~`", "- Ours `~REQ001~`", 1)
+		theirs := strings.Replace(base, "- `~REQ001~`", "- Theirs `~REQ001~`", 1)
+		merged, conflicts, err := MergeMarkdown(&MergeConfig{}, "req.md", []byte(base), []byte(ours), []byte(theirs))
+		require.NoError(t, err)
+		require.Equal(t, 1, conflicts)
+		require.Contains(t, string(merged), "<<<<<<< ours\n- Ours `~REQ001~`covrd[^1]✅\n=======\n- Theirs `~REQ001~`covrd[^1]✅\n>>>>>>> theirs\n")
+		require.True(t, strings.HasSuffix(string(merged), "\n\n"+footnote("1", "REQ001", c1)+"\n"))
+	})
+
+	t.Run("summary block", func(t *testing.T) {
+		summary := func(covered, uncovered int) string {
+			return strings.Join(FormatSummaryBlock(SummaryScopeFile, []SummaryRow{{Covered: covered, Uncovered: uncovered}}), "\n") + "\n\n"
+		}
+		base := header + summary(1, 0) +
+			"- `~REQ001~`covrd[^1]✅\n" +
+			"\n" +
+			footnote("1", "REQ001", c1) + "\n"
+
+		// Both sides add a requirement, so both change the summary
+		ours := header + summary(2, 0) +
+			"- `~REQ001~`covrd[^1]✅\n" +
+			"- `~REQ002~`covrd[^2]✅\n" +
+			"\n" +
+			footnote("1", "REQ001", c1) + "\n" +
+			footnote("2", "REQ002", c2) + "\n"
+		theirs := header + summary(1, 1) +
+			"Intro\n\n" +
+			"- `~REQ003~`uncvrd[^2]❓\n" +
+			"- `~REQ001~`covrd[^1]✅\n" +
+			"\n" +
+			footnote("1", "REQ001", c1) + "\n" +
+			footnote("2", "REQ003") + "\n"
+
+		merged, conflicts, err := MergeMarkdown(&MergeConfig{}, "req.md", []byte(base), []byte(ours), []byte(theirs))
+		require.NoError(t, err)
+		require.Zero(t, conflicts)
+
+		// The summary block is left as a bare begin marker, the next trace fills it in
+		want := header +
+			"<!-- reqmd:summary -->\n" +
+			"\n" +
+			"Intro\n\n" +
+			"- `~REQ003~`uncvrd[^3]❓\n" +
+			"- `~REQ001~`covrd[^1]✅\n" +
+			"- `~REQ002~`covrd[^2]✅\n" +
+			"\n" +
+			footnote("1", "REQ001", c1) + "\n" +
+			footnote("2", "REQ002", c2) + "\n" +
+			footnote("3", "REQ003") + "\n"
+		require.Equal(t, want, string(merged))
+	})
+}
@@ -5,6 +5,7 @@ package internal
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -53,6 +54,31 @@ func StrToReqId(reqStr string) RequirementId {
 }
 
 type CoverageFootnoteId string
+
+// FootnoteIdStrategy defines how CoverageFootnoteIds of new footnotes are generated
+type FootnoteIdStrategy string
+
+const (
+	FootnoteIdsNumeric FootnoteIdStrategy = "numeric" // "1", "2", ... per file
+	FootnoteIdsName    FootnoteIdStrategy = "name"    // derived from RequirementName, ref. NamedFootnoteId()
+)
+
+// ParseFootnoteIdStrategy returns FootnoteIdsNumeric for the empty string
+func ParseFootnoteIdStrategy(s string) (FootnoteIdStrategy, error) {
+	switch FootnoteIdStrategy(s) {
+	case "", FootnoteIdsNumeric:
+		return FootnoteIdsNumeric, nil
+	case FootnoteIdsName:
+		return FootnoteIdsName, nil
+	}
+	return "", fmt.Errorf("unknown footnote id strategy, shall be %s or %s: %s", FootnoteIdsNumeric, FootnoteIdsName, s)
+}
+
+// NamedFootnoteId returns the stable CoverageFootnoteId of the requirement, e.g. "~Post.handler~" for "Post.handler"
+func NamedFootnoteId(name RequirementName) CoverageFootnoteId {
+	return CoverageFootnoteId("~" + string(name) + "~")
+}
+
 type FilePath = string
 type FolderPath = string
 
@@ -82,15 +108,59 @@ type FileStructure struct {
 	Requirements      []RequirementSite  // for Markdown: discovered requirements (bare or annotated)
 	CoverageFootnotes []CoverageFootnote // for Markdown: discovered coverage footnotes
 	CoverageTags      []CoverageTag      // for source: discovered coverage tags
-	FileHash          string             // git hash of the file
-	RepoRootFolderURL string
-	RelativePath      string
+	SummaryBlocks     []SummaryBlock     // for Markdown: discovered coverage summary blocks
+	// for Markdown: CoverageFootnoteIds that are referenced by RequirementSites on ignored lines, such footnotes are not orphaned
+	IgnoredFootnoteRefs map[CoverageFootnoteId]bool
+	LastLine            int               // for Markdown: the last non-empty line
+	Suppressions        []Suppression     // `reqmd:ignore` comments
+	SuppressedErrors    []ProcessingError // syntax errors that are suppressed by Suppressions
+	FileHash            string            // git hash of the file
+	RepoRootFolderURL   string
+	RelativePath        string
+	// URL template with {path} and {line} placeholders, e.g. "https://github.com/voedger/reqmd/blob/main/{path}#L{line}".
+	// If empty, CoverageURLs are constructed from RepoRootFolderURL.
+	// If both are empty, CoverageURLs are relative links, ref. RelativeCoverageURL()
+	CoverageURLTemplate string
 }
 
+// URL template placeholders
+const (
+	URLPlaceholderBase = "{base}" // repository URL, e.g. "https://github.com/voedger/reqmd"
+	URLPlaceholderRef  = "{ref}"  // CommitRef, e.g. "main"
+	URLPlaceholderPath = "{path}" // slashed file path relative to the repository root
+	URLPlaceholderLine = "{line}" // line number
+)
+
 func (f *FileStructure) FileURL() string {
 	return f.RepoRootFolderURL + "/" + filepath.ToSlash(f.RelativePath)
 }
 
+// HasBaseURL returns false if the file has no URL and shall be referenced by relative links
+func (f *FileStructure) HasBaseURL() bool {
+	return f.RepoRootFolderURL != "" || f.CoverageURLTemplate != ""
+}
+
+// RelativeCoverageURL returns the link to the given line of the file relative to the folder of the markdown file
+func (f *FileStructure) RelativeCoverageURL(mdFilePath FilePath, line int) string {
+	relPath, err := filepath.Rel(filepath.Dir(mdFilePath), f.Path)
+	if err != nil {
+		relPath = f.RelativePath
+	}
+	link := url.URL{Path: filepath.ToSlash(relPath)}
+	return link.EscapedPath() + "#L" + strconv.Itoa(line)
+}
+
+// CoverageURL returns the URL of the given line of the file
+func (f *FileStructure) CoverageURL(line int) string {
+	if f.CoverageURLTemplate == "" {
+		return f.FileURL() + "#L" + strconv.Itoa(line)
+	}
+	return strings.NewReplacer(
+		URLPlaceholderPath, filepath.ToSlash(f.RelativePath),
+		URLPlaceholderLine, strconv.Itoa(line),
+	).Replace(f.CoverageURLTemplate)
+}
+
 // RequirementSite represents a single requirement reference discovered in a Markdown file.
 type RequirementSite struct {
 	Line                int                // line number where the requirement is defined/referenced
@@ -109,17 +179,20 @@ var RequirementSiteRegex = regexp.MustCompile(
 		"\\s*(✅|❓)?" + // Optional CoverageStatusEmoji
 		")?")
 
+// CoverageStatusEmojiOf returns the CoverageStatusEmoji that matches the CoverageStatusWord:
+// ✅ for "covrd" and "covered", ❓ otherwise
+func CoverageStatusEmojiOf(coverageStatusWord CoverageStatusWord) CoverageStatusEmoji {
+	if coverageStatusWord == CoverageStatusWordCovered || coverageStatusWord == CoverageStatusWordCovrd {
+		return CoverageStatusEmojiCovered
+	}
+	return CoverageStatusEmojiUncvrd
+}
+
 // Build a string representation of the RequirementSite according to the requirements
 // CoverageStatusEmoji is ✅ for "covered", and ❓ for "uncvrd"
 func FormatRequirementSite(requirementName RequirementName, coverageStatusWord CoverageStatusWord, footnoteId CoverageFootnoteId) string {
 	lbl := fmt.Sprintf("`~%s~`", requirementName)
-
-	emoji := CoverageStatusEmojiUncvrd
-	if coverageStatusWord == CoverageStatusWordCovered || coverageStatusWord == CoverageStatusWordCovrd {
-		emoji = CoverageStatusEmojiCovered
-	}
-
-	return fmt.Sprintf("%s%s[^%s]%s", lbl, coverageStatusWord, footnoteId, emoji)
+	return fmt.Sprintf("%s%s[^%s]%s", lbl, coverageStatusWord, footnoteId, CoverageStatusEmojiOf(coverageStatusWord))
 }
 
 // CoverageTag represents a coverage marker found in source code.
@@ -148,6 +221,8 @@ var (
 		"(?:`\\[~([^~/]+)/([^~]+)~([^\\]]+)?\\]`)?" + // Hint with package and coverage type
 		`(?:\s*(.+))?\s*$`) // Optional coverer list
 	CovererRegex = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	// Full SHA-1 or SHA-256 commit hash, e.g. in "https://github.com/voedger/reqmd/blob/<hash>/main.go#L10"
+	commitHashRegex = regexp.MustCompile(`(?:^|[^0-9a-f])(?:[0-9a-f]{64}|[0-9a-f]{40})(?:[^0-9a-f]|$)`)
 )
 
 // Sort Coverers according to requirements:
@@ -221,13 +296,87 @@ func FileURL(coverageURL string) string {
 	return coverageURL[:idx]
 }
 
+// SummaryScope defines which RequirementSites are counted in a SummaryBlock
+type SummaryScope string
+
+const (
+	SummaryScopeFile    SummaryScope = "file"    // RequirementSites of the file that contains the block
+	SummaryScopePackage SummaryScope = "package" // RequirementSites of all files with the same PackageId
+)
+
+// SummaryBlock is a region of a markdown file that starts with a SummaryBeginMarker
+// and ends with a SummaryEndMarker. The content of the region is maintained by the tool.
+//
+// Example:
+//
+//	<!-- reqmd:summary -->
+//	| Type | Covered | Uncovered | Total |
+//	| ---- | ------- | --------- | ----- |
+//	| cmp  | 1       | 1         | 2     |
+//	| **Total** | 1  | 1         | 2     |
+//	<!-- /reqmd:summary -->
+type SummaryBlock struct {
+	Line    int          // line number of the begin marker
+	EndLine int          // line number of the end marker, equals Line if there is no end marker yet
+	Scope   SummaryScope // "file" if omitted in the begin marker
+	Content []string     // lines between the markers
+}
+
+var (
+	SummaryBeginMarkerRegex = regexp.MustCompile(`^\s*<!--\s*reqmd:summary(?:\s+(file|package))?\s*-->\s*$`)
+	SummaryEndMarkerRegex   = regexp.MustCompile(`^\s*<!--\s*/reqmd:summary\s*-->\s*$`)
+)
+
+// SummaryRow holds coverage counters for a single requirement type
+type SummaryRow struct {
+	Type      string // first segment of the RequirementName, empty if the name has no segments
+	Covered   int
+	Uncovered int
+}
+
+// FormatSummaryBlock builds all lines of the SummaryBlock, including the markers.
+// Rows are expected to be sorted by the caller.
+func FormatSummaryBlock(scope SummaryScope, rows []SummaryRow) []string {
+	begin := "<!-- reqmd:summary -->"
+	if scope == SummaryScopePackage {
+		begin = "<!-- reqmd:summary package -->"
+	}
+	lines := []string{
+		begin,
+		"| Type | Covered | Uncovered | Total |",
+		"| ---- | ------- | --------- | ----- |",
+	}
+	var total SummaryRow
+	for _, row := range rows {
+		reqType := row.Type
+		if reqType == "" {
+			reqType = "-"
+		}
+		lines = append(lines, fmt.Sprintf("| %s | %d | %d | %d |", reqType, row.Covered, row.Uncovered, row.Covered+row.Uncovered))
+		total.Covered += row.Covered
+		total.Uncovered += row.Uncovered
+	}
+	lines = append(lines, fmt.Sprintf("| **Total** | %d | %d | %d |", total.Covered, total.Uncovered, total.Covered+total.Uncovered))
+	return append(lines, "<!-- /reqmd:summary -->")
+}
+
 type ProcessingError struct {
-	Code     string // error code (e.g., "pkgident")
-	FilePath string // file that has a syntax error
-	Line     int    // line number where the syntax error is detected
-	Message  string // human-readable description
+	Code     string   // error code (e.g., "pkgident")
+	FilePath string   // file that has a syntax error
+	Line     int      // line number where the syntax error is detected
+	Message  string   // human-readable description
+	Severity Severity // SeverityError if empty, ref. Severities
 }
 
+// Severity of the ProcessingError, ref. defaultSeverities
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // Processing is aborted
+	SeverityWarning Severity = "warning" // Printed, processing continues
+	SeverityInfo    Severity = "info"    // Printed in verbose mode only, processing continues
+)
+
 // Collection of ProcessingErrors
 // Implements Error interface
 type ProcessingErrors struct {
@@ -241,7 +390,7 @@ func (e *ProcessingErrors) Error() string {
 
 	var msgs []string
 	for _, err := range e.Errors {
-		msgs = append(msgs, fmt.Sprintf("%s:%d: %s: %s", err.FilePath, err.Line, err.Code, err.Message))
+		msgs = append(msgs, fmt.Sprintf("%s:%d: %s: %s%s", err.FilePath, err.Line, err.Code, err.Message, explainHint(err.Code)))
 	}
 	return strings.Join(msgs, "\n")
 }
@@ -250,6 +399,7 @@ func (e *ProcessingErrors) Error() string {
 type ScannerResult struct {
 	Files            []FileStructure
 	ProcessingErrors []ProcessingError
+	SuppressedErrors []ProcessingError // syntax errors that are suppressed by FileStructure.Suppressions or by the baseline
 }
 
 // MdActionType represents the type of markdown transformation needed.
@@ -258,6 +408,9 @@ type MdActionType string
 const (
 	ActionFootnote MdActionType = "Footnote" // Create/Update a CoverageFootnote
 	ActionSite     MdActionType = "Site"     // Update RequirementSite
+	ActionSummary  MdActionType = "Summary"  // Replace the SummaryBlock region (Line..EndLine)
+
+	ActionFootnoteRemove MdActionType = "FootnoteRemove" // Remove the CoverageFootnote that is not referenced by any RequirementSite
 )
 
 // MdAction describes a single transformation (add/update/delete) to be applied in a file.
@@ -265,7 +418,8 @@ type MdAction struct {
 	Type            MdActionType // e.g., "Footnote", "Site"
 	Path            string       // file path
 	Line            int          // the line number where the change is applied. 0 means the
-	Data            string       // new data (if any)
+	EndLine         int          // the last line of the replaced region, used by ActionSummary
+	Data            string       // new data (if any), lines are separated by "\n"
 	RequirementName RequirementName
 }
 
@@ -278,4 +432,5 @@ func (a *MdAction) String() string {
 type AnalyzerResult struct {
 	MdActions        map[FilePath][]MdAction
 	ProcessingErrors []ProcessingError
+	SuppressedErrors []ProcessingError // semantic errors that are suppressed by FileStructure.Suppressions or by the baseline
 }
@@ -0,0 +1,77 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewNoVCS returns IVCS for source trees without a git repository (exported trees, tarballs etc.)
+//   - path is the root of the tree, RelativePaths are relative to it
+//   - All files are treated as tracked, FileHash is the git blob hash of the file content
+//   - baseURL is either a URL template with {path} and {line} placeholders or a base URL that is followed by "/{path}#L{line}"
+//   - If baseURL is empty, CoverageURLs are relative links from the markdown files, ref. FileStructure.RelativeCoverageURL()
+func NewNoVCS(path string, baseURL string) (IVCS, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		absPath = filepath.Dir(absPath)
+	}
+
+	v := &noVCS{pathToRoot: filepath.ToSlash(absPath)}
+	if baseURL != "" {
+		v.urlTemplate = strings.TrimSuffix(baseURL, "/") + "/" + URLPlaceholderPath + "#L" + URLPlaceholderLine
+		if strings.Contains(baseURL, "{") {
+			if err := validateURLTemplate(baseURL); err != nil {
+				return nil, err
+			}
+			v.urlTemplate = baseURL
+		}
+		v.repoRootFolderURL = repoRootFolderURL(v.urlTemplate)
+	}
+	return v, nil
+}
+
+type noVCS struct {
+	pathToRoot        string
+	urlTemplate       string
+	repoRootFolderURL string
+}
+
+func (v *noVCS) PathToRoot() string {
+	return v.pathToRoot
+}
+
+func (v *noVCS) FileHash(filePath string) (relPath, hash string, err error) {
+	relPath, err = filepath.Rel(v.pathToRoot, filePath)
+	if err != nil {
+		return "", "", err
+	}
+	hash, err = fileBlobHash(filePath)
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.ToSlash(relPath), hash, nil
+}
+
+func (v *noVCS) RepoRootFolderURL() string {
+	return v.repoRootFolderURL
+}
+
+func (v *noVCS) CoverageURLTemplate() string {
+	return v.urlTemplate
+}
+
+func (v *noVCS) ReadFile(filePath string) ([]byte, error) {
+	return os.ReadFile(filePath)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoVCS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+	srcPath := filepath.Join(dir, "src", "impl.go")
+	require.NoError(t, os.WriteFile(srcPath, []byte("hello\n"), 0644))
+
+	t.Run("FileHash", func(t *testing.T) {
+		vcs, err := NewNoVCS(dir, "")
+		require.NoError(t, err)
+		relPath, hash, err := vcs.FileHash(srcPath)
+		require.NoError(t, err)
+		require.Equal(t, "src/impl.go", relPath)
+		// git hash-object of "hello\n"
+		require.Equal(t, "ce013625030ba8dba906f756967f9e9ca394464a", hash)
+	})
+
+	t.Run("base URL", func(t *testing.T) {
+		vcs, err := NewNoVCS(dir, "https://example.com/release/1.0/")
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/release/1.0", vcs.RepoRootFolderURL())
+		file := FileStructure{RelativePath: "src/impl.go", CoverageURLTemplate: vcs.CoverageURLTemplate()}
+		require.Equal(t, "https://example.com/release/1.0/src/impl.go#L3", file.CoverageURL(3))
+	})
+
+	t.Run("URL template", func(t *testing.T) {
+		vcs, err := NewNoVCS(dir, "https://example.com/browse?file={path}&line={line}")
+		require.NoError(t, err)
+		file := FileStructure{RelativePath: "src/impl.go", CoverageURLTemplate: vcs.CoverageURLTemplate()}
+		require.Equal(t, "https://example.com/browse?file=src/impl.go&line=3", file.CoverageURL(3))
+
+		_, err = NewNoVCS(dir, "https://example.com/browse#{line}")
+		require.ErrorContains(t, err, "{path}")
+	})
+}
+
+// Source tree without .git is traced, CoverageURLs are relative links from markdown files
+func TestNoVCS_Trace(t *testing.T) {
+	dir := t.TempDir()
+	mdPath := filepath.Join(dir, "docs", "req.md")
+	srcPath := filepath.Join(dir, "src", "my impl.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(mdPath), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(srcPath), 0755))
+	require.NoError(t, os.WriteFile(mdPath, []byte("---\nreqmd.package: pkg\n---\n\n- `~REQ001~`\n"), 0644))
+	require.NoError(t, os.WriteFile(srcPath, []byte("package src\n\n// [~pkg/REQ001~impl]\n"), 0644))
+
+	scfg := &ScannerConfig{NoVCS: true}
+	tracer := NewTracer(NewScanner(scfg), NewAnalyzer(), NewApplier(&ApplierConfig{}), NewVerifier(scfg, NewAnalyzer), []string{dir})
+	require.NoError(t, tracer.Trace(t.Context()))
+
+	content, err := os.ReadFile(mdPath)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "[src/my impl.go:3:impl](../src/my%20impl.go#L3)")
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Conflict markers that are written by git, the size is defaultConflictMarkerSize
+var (
+	conflictMarkerOurs   = strings.Repeat("<", defaultConflictMarkerSize)
+	conflictMarkerBase   = strings.Repeat("|", defaultConflictMarkerSize)
+	conflictMarkerSep    = strings.Repeat("=", defaultConflictMarkerSize)
+	conflictMarkerTheirs = strings.Repeat(">", defaultConflictMarkerSize)
+)
+
+// isConflictMarker returns true if the line is the given conflict marker optionally followed by a label
+func isConflictMarker(line, marker string) bool {
+	rest, ok := strings.CutPrefix(line, marker)
+	return ok && (rest == "" || rest[0] == ' ')
+}
+
+// hasConflictMarkers returns true if the lines contain conflict markers outside markdown code blocks
+func hasConflictMarkers(lines []string) bool {
+	inCodeBlock := false
+	for _, line := range lines {
+		switch {
+		case isCodeBlockMarker(line):
+			inCodeBlock = !inCodeBlock
+		case !inCodeBlock && isConflictMarker(line, conflictMarkerOurs):
+			return true
+		}
+	}
+	return false
+}
+
+// conflictVersions is the content of a conflicted file split into versions
+type conflictVersions struct {
+	ours, base, theirs []string
+	hasBase            bool // diff3 conflict style
+	conflicts          int
+}
+
+// splitConflictVersions splits lines with conflict markers into ours, base and theirs versions.
+// Lines outside conflict blocks belong to all versions, markers in markdown code blocks outside conflict blocks are not conflicts.
+func splitConflictVersions(path string, lines []string) (*conflictVersions, error) {
+	const (
+		sectionNone = iota
+		sectionOurs
+		sectionBase
+		sectionTheirs
+	)
+	v := &conflictVersions{}
+	section := sectionNone
+	start := 0
+	inCodeBlock := false
+	for i, line := range lines {
+		if section == sectionNone && isCodeBlockMarker(line) {
+			inCodeBlock = !inCodeBlock
+		}
+		switch {
+		case isConflictMarker(line, conflictMarkerOurs) && section == sectionNone && !inCodeBlock:
+			section, start = sectionOurs, i+1
+			v.conflicts++
+			continue
+		case isConflictMarker(line, conflictMarkerBase) && section == sectionOurs:
+			section = sectionBase
+			v.hasBase = true
+			continue
+		case line == conflictMarkerSep && (section == sectionOurs || section == sectionBase):
+			section = sectionTheirs
+			continue
+		case isConflictMarker(line, conflictMarkerTheirs) && section == sectionTheirs:
+			section = sectionNone
+			continue
+		}
+		switch section {
+		case sectionNone:
+			v.ours = append(v.ours, line)
+			v.base = append(v.base, line)
+			v.theirs = append(v.theirs, line)
+		case sectionOurs:
+			v.ours = append(v.ours, line)
+		case sectionBase:
+			v.base = append(v.base, line)
+		case sectionTheirs:
+			v.theirs = append(v.theirs, line)
+		}
+	}
+	if section != sectionNone {
+		return nil, fmt.Errorf("%s:%d: conflict block is not terminated", path, start)
+	}
+	return v, nil
+}
+
+// ResolveConflicts resolves conflicts in the content of the markdown file that are limited to RequirementSites and coverage footnotes:
+//   - Annotations of both sides are dropped and regenerated the same way as the merge driver does, ref. MergeMarkdown()
+//   - Lines that consist of a RequirementSite only are annotation content, ref. mergeSiteLines()
+//   - The rest of the prose is merged using the base (diff3 conflict style) or shall be equal, otherwise the file is refused
+//
+// Coverers shall be refreshed by tracing afterwards. resolved is nil if the content has no conflicts.
+func ResolveConflicts(path string, content []byte) (resolved []byte, err error) {
+	lines, hasCRLF := splitLinesPreserveEndings(content)
+	v, err := splitConflictVersions(path, lines)
+	if err != nil {
+		return nil, err
+	}
+	if v.conflicts == 0 {
+		return nil, nil
+	}
+
+	// Without the base, coverers of both sides are kept, they are refreshed by trace anyway
+	var base []byte
+	if v.hasBase {
+		base = joinLinesPreserveEndings(v.base, hasCRLF)
+	}
+	ours := joinLinesPreserveEndings(v.ours, hasCRLF)
+	theirs := joinLinesPreserveEndings(v.theirs, hasCRLF)
+
+	o, a, b, err := parseMergeVersions(path, base, ours, theirs)
+	if err != nil {
+		return nil, err
+	}
+	siteLinesA, siteLinesB := splitSiteLines(a.prose), splitSiteLines(b.prose)
+	var siteLinesO *siteLines
+	if v.hasBase {
+		siteLinesO = splitSiteLines(o.prose)
+	} else {
+		if !slices.Equal(siteLinesA.prose, siteLinesB.prose) {
+			return nil, fmt.Errorf("%s: prose conflicts can not be resolved by reqmd, resolve them manually", path)
+		}
+		// The common prose without RequirementSites, so sites of both sides are kept
+		siteLinesO = splitSiteLines(siteLinesA.prose)
+	}
+
+	lines, conflicts := mergeSiteLines(siteLinesO, siteLinesA, siteLinesB)
+	if conflicts > 0 {
+		return nil, fmt.Errorf("%s: prose conflicts can not be resolved by reqmd, resolve them manually", path)
+	}
+	return annotateMerged(path, lines, o, a, b)
+}
+
+// siteLineRegex matches the rest of the line that consists of a RequirementSite only: an optional list marker
+var siteLineRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])?\s*$`)
+
+// isSiteLine returns true if the line consists of a single RequirementSite, optionally as a list item
+func isSiteLine(line string) bool {
+	loc := RequirementSiteRegex.FindStringIndex(line)
+	return loc != nil && siteLineRegex.MatchString(line[:loc[0]]) && strings.TrimSpace(line[loc[1]:]) == ""
+}
+
+// siteLines is a version of the prose split into prose lines and lines that consist of a RequirementSite only
+type siteLines struct {
+	prose []string
+	sites [][]string // sites[x] follow prose[x-1], sites[0] precede prose[0]
+}
+
+func splitSiteLines(lines []string) *siteLines {
+	s := &siteLines{sites: make([][]string, 1)}
+	inCodeBlock := false
+	for _, line := range lines {
+		if isCodeBlockMarker(line) {
+			inCodeBlock = !inCodeBlock
+		}
+		if !inCodeBlock && isSiteLine(line) {
+			s.sites[len(s.prose)] = append(s.sites[len(s.prose)], line)
+			continue
+		}
+		s.prose = append(s.prose, line)
+		s.sites = append(s.sites, nil)
+	}
+	return s
+}
+
+// sitesAfter returns RequirementSite lines that follow prose lines from..to-1
+func (s *siteLines) sitesAfter(from, to int) []string {
+	var res []string
+	for x := from; x < to; x++ {
+		res = append(res, s.sites[x+1]...)
+	}
+	return res
+}
+
+// mergeSiteLines merges prose lines line by line using the common ancestor o and RequirementSite lines as sets, ref. mergeSites().
+// Sites follow the prose line they follow in their version, sites that follow a changed chunk follow the merged chunk.
+// conflicts is the number of chunks of prose lines that are changed by both sides differently, such chunks are skipped.
+func mergeSiteLines(o, a, b *siteLines) (merged []string, conflicts int) {
+	merged = mergeSites(o.sites[0], a.sites[0], b.sites[0])
+	for _, r := range merge3Regions(o.prose, a.prose, b.prose) {
+		if r.stable {
+			for x, line := range r.o {
+				merged = append(merged, line)
+				merged = append(merged, mergeSites(o.sites[r.oStart+x+1], a.sites[r.aStart+x+1], b.sites[r.bStart+x+1])...)
+			}
+			continue
+		}
+		lines, ok := r.merge()
+		if !ok {
+			conflicts++
+			continue
+		}
+		merged = append(merged, lines...)
+		merged = append(merged, mergeSites(
+			o.sitesAfter(r.oStart, r.oStart+len(r.o)),
+			a.sitesAfter(r.aStart, r.aStart+len(r.a)),
+			b.sitesAfter(r.bStart, r.bStart+len(r.b)),
+		)...)
+	}
+	return merged, conflicts
+}
+
+// mergeSites merges RequirementSite lines: lines that are added by either side are added, lines that are removed by either side are removed.
+// Lines of ours go first
+func mergeSites(o, a, b []string) []string {
+	var res []string
+	for _, line := range slices.Concat(a, b) {
+		if slices.Contains(res, line) {
+			continue
+		}
+		inA, inB := slices.Contains(a, line), slices.Contains(b, line)
+		if inA && inB || !slices.Contains(o, line) {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// ResolveConflictedFiles resolves markdown files under paths that contain conflict markers, ref. ResolveConflicts().
+// Files are written only if all of them are resolved, the same way as the applier does, ref. commitMdFileChanges(). Returns the resolved files.
+func ResolveConflictedFiles(paths []string) ([]string, error) {
+	files, err := findConflictedMdFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var resolvedFiles []string
+	var changes []*mdFileChange
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := ResolveConflicts(file, content)
+		if err != nil {
+			return nil, err
+		}
+		if resolved != nil {
+			resolvedFiles = append(resolvedFiles, file)
+			changes = append(changes, &mdFileChange{
+				path:     file,
+				mode:     info.Mode().Perm(),
+				original: content,
+				updated:  resolved,
+			})
+		}
+	}
+	if err := commitMdFileChanges(changes); err != nil {
+		return nil, err
+	}
+	return resolvedFiles, nil
+}
+
+// findConflictedMdFiles returns markdown files under paths that contain conflict markers
+func findConflictedMdFiles(paths []string) ([]string, error) {
+	var res []string
+	err := walkMdFiles(paths, func(path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if lines, _ := splitLinesPreserveEndings(content); hasConflictMarkers(lines) {
+			res = append(res, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// walkMdFiles calls fn for markdown files under paths, `.*` folders are skipped
+func walkMdFiles(paths []string, fn func(path string) error) error {
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.EqualFold(filepath.Ext(path), markdownExtension) {
+				return nil
+			}
+			return fn(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
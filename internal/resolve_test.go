@@ -0,0 +1,234 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+
+	t.Run("annotations", func(t *testing.T) {
+		content := header +
+			"<<<<<<< HEAD\n" +
+			"- `~REQ001~`covrd[^1]✅\n" +
+			"- `~REQ002~`uncvrd[^2]❓\n" +
+			"=======\n" +
+			"- `~REQ001~`uncvrd[^2]❓\n" +
+			"- `~REQ002~`covrd[^1]✅\n" +
+			">>>>>>> feature\n" +
+			"\n" +
+			"<<<<<<< HEAD\n" +
+			"[^1]: `[~pkg/REQ001~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n" +
+			"[^2]: `[~pkg/REQ002~impl]`\n" +
+			"=======\n" +
+			"[^1]: `[~pkg/REQ002~impl]` [b.go:1:impl](https://example.com/b.go#L1)\n" +
+			"[^2]: `[~pkg/REQ001~impl]`\n" +
+			">>>>>>> feature\n"
+
+		resolved, err := ResolveConflicts("req.md", []byte(content))
+		require.NoError(t, err)
+
+		// Ids of ours are kept, coverers are refreshed by trace
+		require.Equal(t, header+
+			"- `~REQ001~`covrd[^1]✅\n"+
+			"- `~REQ002~`covrd[^2]✅\n"+
+			"\n"+
+			"[^1]: `[~pkg/REQ001~impl]` [a.go:1:impl](https://example.com/a.go#L1)\n"+
+			"[^2]: `[~pkg/REQ002~impl]` [b.go:1:impl](https://example.com/b.go#L1)\n",
+			string(resolved))
+	})
+
+	t.Run("sites added by both sides", func(t *testing.T) {
+		content := header +
+			"- `~REQ001~`uncvrd[^1]❓\n" +
+			"<<<<<<< HEAD\n" +
+			"- `~REQ002~`uncvrd[^2]❓\n" +
+			"=======\n" +
+			"- `~REQ003~`uncvrd[^2]❓\n" +
+			">>>>>>> feature\n" +
+			"\n" +
+			"[^1]: `[~pkg/REQ001~impl]`\n" +
+			"<<<<<<< HEAD\n" +
+			"[^2]: `[~pkg/REQ002~impl]`\n" +
+			"=======\n" +
+			"[^2]: `[~pkg/REQ003~impl]`\n" +
+			">>>>>>> feature\n"
+
+		resolved, err := ResolveConflicts("req.md", []byte(content))
+		require.NoError(t, err)
+		require.Equal(t, header+
+			"- `~REQ001~`uncvrd[^1]❓\n"+
+			"- `~REQ002~`uncvrd[^2]❓\n"+
+			"- `~REQ003~`uncvrd[^3]❓\n"+
+			"\n"+
+			"[^1]: `[~pkg/REQ001~impl]`\n"+
+			"[^2]: `[~pkg/REQ002~impl]`\n"+
+			"[^3]: `[~pkg/REQ003~impl]`\n",
+			string(resolved))
+	})
+
+	t.Run("diff3", func(t *testing.T) {
+		// Ours replaces REQ001 with REQ002, theirs adds REQ003
+		content := header +
+			"Intro\n" +
+			"<<<<<<< HEAD\n" +
+			"- `~REQ002~`uncvrd[^2]❓\n" +
+			"||||||| base\n" +
+			"- `~REQ001~`uncvrd[^1]❓\n" +
+			"=======\n" +
+			"- `~REQ001~`uncvrd[^1]❓\n" +
+			"- `~REQ003~`uncvrd[^2]❓\n" +
+			">>>>>>> feature\n" +
+			"\n" +
+			"<<<<<<< HEAD\n" +
+			"[^2]: `[~pkg/REQ002~impl]`\n" +
+			"||||||| base\n" +
+			"[^1]: `[~pkg/REQ001~impl]`\n" +
+			"=======\n" +
+			"[^1]: `[~pkg/REQ001~impl]`\n" +
+			"[^2]: `[~pkg/REQ003~impl]`\n" +
+			">>>>>>> feature\n"
+
+		resolved, err := ResolveConflicts("req.md", []byte(content))
+		require.NoError(t, err)
+		require.Equal(t, header+
+			"Intro\n"+
+			"- `~REQ002~`uncvrd[^2]❓\n"+
+			"- `~REQ003~`uncvrd[^3]❓\n"+
+			"\n"+
+			"[^2]: `[~pkg/REQ002~impl]`\n"+
+			"[^3]: `[~pkg/REQ003~impl]`\n",
+			string(resolved))
+	})
+
+	t.Run("diff3 prose", func(t *testing.T) {
+		content := header +
+			"<<<<<<< HEAD\n" +
+			"Ours\n" +
+			"- `~REQ002~`\n" +
+			"||||||| base\n" +
+			"Base\n" +
+			"=======\n" +
+			"Theirs\n" +
+			">>>>>>> feature\n"
+		_, err := ResolveConflicts("req.md", []byte(content))
+		require.ErrorContains(t, err, "prose conflicts")
+	})
+
+	t.Run("summary block", func(t *testing.T) {
+		content := header +
+			"<!-- reqmd:summary -->\n" +
+			"| Type | Covered | Uncovered | Total |\n" +
+			"| ---- | ------- | --------- | ----- |\n" +
+			"<<<<<<< HEAD\n" +
+			"| **Total** | 0 | 2 | 2 |\n" +
+			"=======\n" +
+			"| **Total** | 1 | 1 | 2 |\n" +
+			">>>>>>> feature\n" +
+			"<!-- /reqmd:summary -->\n" +
+			"\n" +
+			"- `~REQ001~`\n"
+
+		// The summary block is filled in by trace
+		resolved, err := ResolveConflicts("req.md", []byte(content))
+		require.NoError(t, err)
+		require.Equal(t, header+
+			"<!-- reqmd:summary -->\n"+
+			"\n"+
+			"- `~REQ001~`\n",
+			string(resolved))
+	})
+
+	t.Run("no conflicts", func(t *testing.T) {
+		resolved, err := ResolveConflicts("req.md", []byte(header+"- `~REQ001~`\n"))
+		require.NoError(t, err)
+		require.Nil(t, resolved)
+	})
+
+	t.Run("fenced example", func(t *testing.T) {
+		content := header +
+			"```markdown\n" +
+			"<<<<<<< HEAD\n" +
+			"Ours\n" +
+			"=======\n" +
+			"Theirs\n" +
+			">>>>>>> feature\n" +
+			"```\n"
+		resolved, err := ResolveConflicts("req.md", []byte(content))
+		require.NoError(t, err)
+		require.Nil(t, resolved)
+	})
+
+	t.Run("prose", func(t *testing.T) {
+		content := header +
+			"<<<<<<< HEAD\n" +
+			"- Ours `~REQ001~`covrd[^1]✅\n" +
+			"=======\n" +
+			"- Theirs `~REQ001~`covrd[^1]✅\n" +
+			">>>>>>> feature\n"
+		_, err := ResolveConflicts("req.md", []byte(content))
+		require.ErrorContains(t, err, "prose conflicts")
+	})
+
+	t.Run("unterminated", func(t *testing.T) {
+		_, err := ResolveConflicts("req.md", []byte(header+"<<<<<<< HEAD\n- `~REQ001~`\n"))
+		require.ErrorContains(t, err, "req.md:5: conflict block is not terminated")
+	})
+}
+
+func TestResolveConflictedFiles(t *testing.T) {
+	dir := t.TempDir()
+	const header = "---\nreqmd.package: pkg\n---\n\n"
+
+	clean := header + "- `~REQ001~`\n"
+	conflicted := header +
+		"<<<<<<< HEAD\n" +
+		"- `~REQ001~`covrd[^1]✅\n" +
+		"=======\n" +
+		"- `~REQ001~`covrd[^2]✅\n" +
+		">>>>>>> feature\n"
+	prose := header +
+		"<<<<<<< HEAD\n" +
+		"Ours\n" +
+		"=======\n" +
+		"Theirs\n" +
+		">>>>>>> feature\n"
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	read := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	cleanPath := write("clean.md", clean)
+	conflictedPath := write("conflicted.md", conflicted)
+	prosePath := write("prose.md", prose)
+	// Conflict examples in code blocks are not conflicts
+	example := header + "```\n<<<<<<< HEAD\nOurs\n=======\nTheirs\n>>>>>>> feature\n```\n"
+	examplePath := write("example.md", example)
+
+	// Nothing is written if any file can not be resolved
+	_, err := ResolveConflictedFiles([]string{dir})
+	require.ErrorContains(t, err, "prose.md")
+	require.Equal(t, conflicted, read(conflictedPath))
+
+	require.NoError(t, os.Remove(prosePath))
+	files, err := ResolveConflictedFiles([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, []string{conflictedPath}, files)
+	require.Equal(t, clean, read(cleanPath))
+	require.Equal(t, example, read(examplePath))
+	require.Equal(t, header+"- `~REQ001~`uncvrd[^1]❓\n\n[^1]: `[~pkg/REQ001~impl]`\n", read(conflictedPath))
+}
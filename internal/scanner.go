@@ -4,10 +4,14 @@
 package internal
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,8 +24,8 @@ const (
 	gitFolderName     = ".git"
 
 	// Scanner configuration
-	defaultMaxWorkers      = 32
-	defaultMaxErrQueueSize = 50
+	defaultMaxWorkers = 32
+	defaultMaxErrors  = 50 // default of --max-errors
 
 	// Default source file extensions
 	defaultSourceExtensions = ".c,.cpp,.cs,.dart,.fs,.go,.h,.hpp,.java,.js,.jsx,.kt,.m,.md,.php,.py,.rb,.rs,.scala,.sql,.swift,.ts,.tsx,.vsql,.yaml,.yml"
@@ -46,13 +50,37 @@ type ScannerConfig struct {
 	Extensions     string
 	IgnorePatterns []*regexp.Regexp
 	TypeRegistry   *TypeRegistry
+	GitConfig      *GitConfig // nil means default configuration
+	NoVCS          bool       // Paths are not required to be git repositories, ref. NewNoVCS()
+	BaseURL        string     // Base URL or URL template for NoVCS mode
+	// CoverageStatusEmojis that do not match CoverageStatusWords are not errors, they are repaired by the analyzer
+	RepairStatusEmoji bool
+	// Overrides default severities of syntax errors
+	Severities Severities
+	// Syntax errors of the baseline are suppressed, nil if there is no baseline
+	Baseline *Baseline
+	// Maximum number of reported scan errors, ref. ScanErrors. No limit if 0
+	MaxErrors int
+	// Scanning stops at the first error of reading folders and files
+	FailFast bool
 }
 
 func NewScanner(scfg *ScannerConfig) IScanner {
 	s := &scanner{
-		sourceExtensions: make(map[string]bool),
-		ignorePatterns:   scfg.IgnorePatterns,
-		typeRegistry:     scfg.TypeRegistry,
+		sourceExtensions:  make(map[string]bool),
+		ignorePatterns:    scfg.IgnorePatterns,
+		typeRegistry:      scfg.TypeRegistry,
+		gitConfig:         scfg.GitConfig,
+		noVCS:             scfg.NoVCS,
+		baseURL:           scfg.BaseURL,
+		repairStatusEmoji: scfg.RepairStatusEmoji,
+		severities:        scfg.Severities,
+		baseline:          scfg.Baseline,
+		maxErrors:         scfg.MaxErrors,
+		failFast:          scfg.FailFast,
+	}
+	if s.gitConfig == nil {
+		s.gitConfig = &GitConfig{}
 	}
 	// Use provided extensions or fallback to defaults
 	exts := scfg.Extensions
@@ -67,7 +95,7 @@ func NewScanner(scfg *ScannerConfig) IScanner {
 }
 
 // Scan scans multiple paths that can each contain both markdown and source files
-func (s *scanner) Scan(paths []string) (*ScannerResult, error) {
+func (s *scanner) Scan(ctx context.Context, paths []string) (*ScannerResult, error) {
 
 	// Reset result
 	res := &ScannerResult{
@@ -82,12 +110,18 @@ func (s *scanner) Scan(paths []string) (*ScannerResult, error) {
 	s.stats.processedBytes.Store(0)
 	s.stats.skippedFiles.Store(0)
 	s.stats.skippedBytes.Store(0)
+	s.stats.untrackedFiles.Store(0)
+	s.untrackedFiles = nil
 
-	err := s.scanPaths(paths)
+	err := s.scanPaths(ctx, paths)
 
 	if err != nil {
 		return nil, err
 	}
+	var baselined []ProcessingError
+	s.result.ProcessingErrors, baselined = s.baseline.split(s.result.ProcessingErrors)
+	s.result.SuppressedErrors = append(s.result.SuppressedErrors, baselined...)
+	s.severities.apply(s.result.ProcessingErrors)
 
 	// Report statistics after scanning is complete
 	Verbose("Scan complete (multi-path)",
@@ -95,24 +129,43 @@ func (s *scanner) Scan(paths []string) (*ScannerResult, error) {
 		"processed size", ByteCountSI(s.stats.processedBytes.Load()),
 		"skipped files", s.stats.skippedFiles.Load(),
 		"skipped size", ByteCountSI(s.stats.skippedBytes.Load()),
+		"untracked files", s.stats.untrackedFiles.Load(),
 		"duration", time.Since(start),
 	)
 
+	if len(s.untrackedFiles) > 0 {
+		sort.Strings(s.untrackedFiles)
+		Verbose("Untracked files are skipped", "count", len(s.untrackedFiles))
+		for _, f := range s.untrackedFiles {
+			Verbose("  " + f)
+		}
+	}
+
 	return s.result, nil
 }
 
 type scanner struct {
-	sourceExtensions map[string]bool
-	ignorePatterns   []*regexp.Regexp
-	typeRegistry     *TypeRegistry
-	stats            struct {
+	sourceExtensions  map[string]bool
+	ignorePatterns    []*regexp.Regexp
+	typeRegistry      *TypeRegistry
+	gitConfig         *GitConfig
+	noVCS             bool
+	baseURL           string
+	repairStatusEmoji bool
+	severities        Severities
+	baseline          *Baseline
+	maxErrors         int
+	failFast          bool
+	stats             struct {
 		processedFiles atomic.Int64
 		processedBytes atomic.Int64
 		skippedFiles   atomic.Int64
 		skippedBytes   atomic.Int64
+		untrackedFiles atomic.Int64
 	}
-	mu     sync.Mutex
-	result *ScannerResult
+	untrackedFiles []string // "path: reason", collected in verbose mode only
+	mu             sync.Mutex
+	result         *ScannerResult
 }
 
 // ByteCountSI converts bytes to human readable string using SI (decimal) units
@@ -130,7 +183,7 @@ func ByteCountSI(b int64) string {
 }
 
 // scanFile handles both markdown and source files in a unified way
-func (s *scanner) scanFile(filePath string, pctx *ScannerContext, igit IVCS) error {
+func (s *scanner) scanFile(ctx context.Context, filePath string, pctx *ScannerContext, igit IVCS) error {
 	filePath = filepath.ToSlash(filePath)
 	ext := strings.ToLower(filepath.Ext(filePath))
 
@@ -166,15 +219,26 @@ func (s *scanner) scanFile(filePath string, pctx *ScannerContext, igit IVCS) err
 	relPath, hash, err := igit.FileHash(filePath)
 	if err != nil {
 		// Skip untracked files
+		s.stats.untrackedFiles.Add(1)
 		if IsVerbose {
-			Verbose("scanFile: skipping untracked file", "path", filePath, "error", err.Error())
+			Verbose("scanFile: skipping untracked file", "path", filePath, "reason", err.Error())
+			s.mu.Lock()
+			s.untrackedFiles = append(s.untrackedFiles, filePath+": "+err.Error())
+			s.mu.Unlock()
 		}
 		return nil
 	}
 
 	// Parse the file once
 
-	structure, errs, err := parseFile(pctx, filePath)
+	content, err := igit.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	structure, errs, err := parseFileEx(pctx, filePath, bytes.NewReader(content))
 	if err != nil {
 		return err
 	}
@@ -184,9 +248,11 @@ func (s *scanner) scanFile(filePath string, pctx *ScannerContext, igit IVCS) err
 		structure.FileHash = hash
 		structure.RelativePath = relPath
 		structure.RepoRootFolderURL = igit.RepoRootFolderURL()
+		structure.CoverageURLTemplate = igit.CoverageURLTemplate()
 
-		// Add to files list if it has requirements or coverage tags
-		if (ext == markdownExtension && len(structure.Requirements) > 0) ||
+		// Add to files list if it has requirements, summary blocks, coverage footnotes or coverage tags.
+		// Footnotes of markdown files without requirements are orphaned and shall be removed
+		if (ext == markdownExtension && (len(structure.Requirements) > 0 || len(structure.SummaryBlocks) > 0 || len(structure.CoverageFootnotes) > 0)) ||
 			(ext != markdownExtension && len(structure.CoverageTags) > 0) {
 			s.mu.Lock()
 			s.result.Files = append(s.result.Files, *structure)
@@ -195,37 +261,187 @@ func (s *scanner) scanFile(filePath string, pctx *ScannerContext, igit IVCS) err
 	}
 
 	// Add any errors found during parsing
-	if len(errs) > 0 {
+	if len(errs) > 0 || (structure != nil && len(structure.SuppressedErrors) > 0) {
 		s.mu.Lock()
 		s.result.ProcessingErrors = append(s.result.ProcessingErrors, errs...)
+		if structure != nil {
+			s.result.SuppressedErrors = append(s.result.SuppressedErrors, structure.SuppressedErrors...)
+		}
 		s.mu.Unlock()
 	}
 
 	return nil
 }
 
-func (s *scanner) scanPaths(paths []string) (err error) {
+// scanPaths scans all paths, errors of all paths are collected and returned as *ScanErrors.
+// ctx.Err() is returned if ctx is done, with failFast paths after the first erroneous one are skipped.
+func (s *scanner) scanPaths(ctx context.Context, paths []string) error {
+	var errs []error
 
 	// Process all paths
 	for _, path := range paths {
-
-		git, err := NewGitVCS(path)
-		if err != nil {
-			return fmt.Errorf("failed to initialize git for path %s: %w", path, err)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.failFast && len(errs) > 0 {
+			break
 		}
 
-		fp := func(filePath string) (FileProcessor, error) {
-			return s.folderProcessor(filePath, git)
+		var fp FolderProcessor
+		if s.noVCS {
+			vcs, err := NewNoVCS(path, s.baseURL)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to initialize path %s: %w", path, err))
+				continue
+			}
+			fp = func(_ context.Context, folderPath string) (FileProcessor, error) {
+				return s.folderProcessor(folderPath, vcs)
+			}
+		} else {
+			git, err := NewGitVCSEx(path, s.gitConfig)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to initialize git for path %s: %w", path, err))
+				continue
+			}
+
+			// Folders are processed one by one, parents before children
+			folderVCSs := make(map[string]IVCS)
+
+			fp = func(ctx context.Context, folderPath string) (FileProcessor, error) {
+				igit, err := s.folderVCS(ctx, folderPath, git, folderVCSs)
+				if err != nil {
+					return nil, err
+				}
+				return s.folderProcessor(folderPath, igit)
+			}
 		}
 
-		if errs := FoldersScanner(defaultMaxWorkers, defaultMaxErrQueueSize, path, fp); len(errs) > 0 {
-			return fmt.Errorf("error scanning files in %s: %v", path, errs[0])
+		errs = append(errs, FoldersScanner(ctx, defaultMaxWorkers, path, fp, s.failFast)...)
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 	}
 
+	if len(errs) > 0 {
+		return NewScanErrors(errs, s.maxErrors)
+	}
 	return nil
 }
 
+// Number of paths that are reported for every cause of ScanErrors, all paths are reported in verbose mode
+const scanErrorSamplePaths = 3
+
+// ScanErrors aggregates errors of scanning, errors with the same cause are reported once with the number of occurrences and sample paths
+type ScanErrors struct {
+	Causes    []string            // unique causes in lexical order
+	Counts    map[string]int      // number of occurrences of every cause
+	Paths     map[string][]string // paths of every cause in lexical order, errors that are not ScanError have no path
+	MaxErrors int                 // maximum number of reported causes, no limit if 0
+}
+
+func NewScanErrors(errs []error, maxErrors int) *ScanErrors {
+	e := &ScanErrors{Counts: make(map[string]int), Paths: make(map[string][]string), MaxErrors: maxErrors}
+	for _, err := range errs {
+		cause := err.Error()
+		var scanErr *ScanError
+		if errors.As(err, &scanErr) {
+			// Errors of the os package contain the path, e.g. "open <path>: permission denied"
+			cause = strings.ReplaceAll(scanErr.Err.Error(), scanErr.Path, "<path>")
+			e.Paths[cause] = append(e.Paths[cause], scanErr.Path)
+		}
+		if e.Counts[cause] == 0 {
+			e.Causes = append(e.Causes, cause)
+		}
+		e.Counts[cause]++
+	}
+	sort.Strings(e.Causes)
+	for _, paths := range e.Paths {
+		sort.Strings(paths)
+	}
+	return e
+}
+
+// Total returns the number of errors including duplicates
+func (e *ScanErrors) Total() int {
+	total := 0
+	for _, count := range e.Counts {
+		total += count
+	}
+	return total
+}
+
+func (e *ScanErrors) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "reqmd: %d error(s) while scanning files", e.Total())
+	if len(e.Causes) != e.Total() {
+		fmt.Fprintf(&sb, ", %d unique", len(e.Causes))
+	}
+	sb.WriteString(":")
+	reported := len(e.Causes)
+	if e.MaxErrors > 0 && reported > e.MaxErrors {
+		reported = e.MaxErrors
+	}
+	for _, cause := range e.Causes[:reported] {
+		sb.WriteString("\n" + cause)
+		if count := e.Counts[cause]; count > 1 {
+			fmt.Fprintf(&sb, " (%d times)", count)
+		}
+		paths := e.Paths[cause]
+		switch {
+		case len(paths) == 0:
+		case IsVerbose:
+			for _, path := range paths {
+				sb.WriteString("\n\t" + path)
+			}
+		case len(paths) > scanErrorSamplePaths:
+			fmt.Fprintf(&sb, ": %s, ... %d more, use -v to list all", strings.Join(paths[:scanErrorSamplePaths], ", "), len(paths)-scanErrorSamplePaths)
+		default:
+			sb.WriteString(": " + strings.Join(paths, ", "))
+		}
+	}
+	if omitted := len(e.Causes) - reported; omitted > 0 {
+		fmt.Fprintf(&sb, "\n... %d more error(s) are omitted, use --max-errors to change the limit", omitted)
+	}
+	return sb.String()
+}
+
+// folderVCS returns the innermost repository of the folder.
+// A folder that contains .git (submodule, nested repository) is the root of a new repository,
+// other folders belong to the repository of the parent folder.
+// If the nested repository can not be opened, the folder belongs to the repository of the parent folder as well.
+// ctx.Err() is returned if ctx is done before the nested repository is opened.
+func (s *scanner) folderVCS(ctx context.Context, folderPath string, rootVCS IVCS, folderVCSs map[string]IVCS) (IVCS, error) {
+	folderPath = filepath.ToSlash(folderPath)
+
+	igit, ok := folderVCSs[filepath.ToSlash(filepath.Dir(folderPath))]
+	if !ok {
+		igit = rootVCS
+	}
+
+	// Dot folders are skipped by folderProcessor
+	if folderPath != igit.PathToRoot() && !strings.HasPrefix(filepath.Base(folderPath), ".") {
+		if _, err := os.Stat(filepath.Join(folderPath, gitFolderName)); err == nil {
+			// Opening a repository loads its index, it takes time for large repositories
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// Ref of the scanned repository is not applicable to nested ones
+			gcfg := *s.gitConfig
+			gcfg.Ref = ""
+			if nested, err := NewGitVCSEx(folderPath, &gcfg); err != nil {
+				// E.g. broken submodule checkout or a repository without remote
+				Verbose("folderVCS: nested repository can not be opened, parent repository is used", "path", folderPath, "error", err)
+			} else {
+				Verbose("folderVCS: nested repository", "path", folderPath, "url", nested.RepoRootFolderURL())
+				igit = nested
+			}
+		}
+	}
+
+	folderVCSs[folderPath] = igit
+	return igit, nil
+}
+
 func (s *scanner) folderProcessor(folderPath string, igit IVCS) (FileProcessor, error) {
 
 	// If folder name starts with a dot, skip it
@@ -236,12 +452,13 @@ func (s *scanner) folderProcessor(folderPath string, igit IVCS) (FileProcessor,
 
 	// Initialize markdown context for this folder
 	pctx := &ScannerContext{
-		TypeRegistry:   s.typeRegistry,
-		IgnorePatterns: s.ignorePatterns,
+		TypeRegistry:      s.typeRegistry,
+		IgnorePatterns:    s.ignorePatterns,
+		RepairStatusEmoji: s.repairStatusEmoji,
 	}
 
-	return func(filePath string) error {
-		return s.scanFile(filePath, pctx, igit)
+	return func(ctx context.Context, filePath string) error {
+		return s.scanFile(ctx, filePath, pctx, igit)
 	}, nil
 
 }
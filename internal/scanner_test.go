@@ -0,0 +1,140 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanErrors(t *testing.T) {
+	errs := []error{
+		&ScanError{Path: "/b.md", Err: errors.New("failed")},
+		&ScanError{Path: "/a.md", Err: errors.New("failed")},
+		errors.New("git: repository not found"),
+		errors.New("git: repository not found"),
+		&ScanError{Path: "/c.md", Err: errors.New("open /c.md: permission denied")},
+	}
+
+	// Errors with the same cause are reported once, the path is not a part of the cause
+	e := NewScanErrors(errs, 0)
+	require.Equal(t, 5, e.Total())
+	require.Equal(t, "reqmd: 5 error(s) while scanning files, 3 unique:\n"+
+		"failed (2 times): /a.md, /b.md\n"+
+		"git: repository not found (2 times)\n"+
+		"open <path>: permission denied: /c.md", e.Error())
+
+	e = NewScanErrors(errs, 2)
+	require.Equal(t, "reqmd: 5 error(s) while scanning files, 3 unique:\n"+
+		"failed (2 times): /a.md, /b.md\n"+
+		"git: repository not found (2 times)\n"+
+		"... 1 more error(s) are omitted, use --max-errors to change the limit", e.Error())
+
+	// Sample paths are reported, all paths are reported in verbose mode
+	errs = nil
+	for i := range 5 {
+		errs = append(errs, &ScanError{Path: fmt.Sprintf("/%d.md", i), Err: errors.New("failed")})
+	}
+	e = NewScanErrors(errs, 0)
+	require.Equal(t, "reqmd: 5 error(s) while scanning files, 1 unique:\n"+
+		"failed (5 times): /0.md, /1.md, /2.md, ... 2 more, use -v to list all", e.Error())
+
+	defer func(verbose bool) { IsVerbose = verbose }(IsVerbose)
+	IsVerbose = true
+	require.Equal(t, "reqmd: 5 error(s) while scanning files, 1 unique:\n"+
+		"failed (5 times)\n\t/0.md\n\t/1.md\n\t/2.md\n\t/3.md\n\t/4.md", e.Error())
+}
+
+// Errors of all paths are reported
+func TestScanner_AllErrors(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := range 3 {
+		paths = append(paths, filepath.Join(dir, fmt.Sprintf("missing%d", i)))
+	}
+
+	_, err := NewScanner(&ScannerConfig{NoVCS: true, MaxErrors: 2}).Scan(t.Context(), paths)
+	var scanErrs *ScanErrors
+	require.ErrorAs(t, err, &scanErrs)
+	require.Equal(t, 3, scanErrs.Total())
+	require.Contains(t, err.Error(), "failed to initialize path "+paths[0])
+	require.Contains(t, err.Error(), "... 1 more error(s) are omitted")
+}
+
+// With FailFast paths after the first erroneous one are skipped
+func TestScanner_FailFast(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "missing0"), filepath.Join(dir, "missing1")}
+
+	_, err := NewScanner(&ScannerConfig{NoVCS: true, FailFast: true}).Scan(t.Context(), paths)
+	var scanErrs *ScanErrors
+	require.ErrorAs(t, err, &scanErrs)
+	require.Equal(t, 1, scanErrs.Total())
+	require.Contains(t, err.Error(), "failed to initialize path "+paths[0])
+}
+
+// Markdown files are not changed if tracing is canceled
+func TestTracer_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	mdPath := writeMd(t, dir, "req.md", "- `~REQ001~`\n")
+	content := mdHeader + "- `~REQ001~`\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "impl.go"), []byte("package src\n\n// [~pkg/REQ001~impl]\n"), 0644))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	scfg := &ScannerConfig{NoVCS: true}
+	err := NewTracer(NewScanner(scfg), NewAnalyzer(), NewApplier(&ApplierConfig{}), NewVerifier(scfg, NewAnalyzer), []string{dir}).Trace(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	actual, err := os.ReadFile(mdPath)
+	require.NoError(t, err)
+	require.Equal(t, content, string(actual))
+}
+
+// Files are not parsed and nested repositories are not opened if ctx is done
+func TestScanner_CanceledFolders(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	dir := t.TempDir()
+	path := writeMd(t, dir, "req.md", "- `~REQ001~`\n")
+	vcs, err := NewNoVCS(dir, "")
+	require.NoError(t, err)
+
+	s := NewScanner(&ScannerConfig{}).(*scanner)
+	s.result = &ScannerResult{}
+	require.ErrorIs(t, s.scanFile(ctx, path, &ScannerContext{}, vcs), context.Canceled)
+	require.Empty(t, s.result.Files)
+
+	nested := filepath.Join(dir, "nested")
+	require.NoError(t, os.MkdirAll(filepath.Join(nested, gitFolderName), 0755))
+	_, err = s.folderVCS(ctx, nested, vcs, map[string]IVCS{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// Nested repository that can not be opened is processed by the parent repository
+func TestScanner_BrokenNestedRepository(t *testing.T) {
+	dir, git := newHookTestRepo(t)
+	srcPath := filepath.Join(dir, "broken", "impl.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(srcPath), 0755))
+	require.NoError(t, os.WriteFile(srcPath, []byte("package broken\n\n// [~pkg/REQ001~impl]\n"), 0644))
+	git("add", ".")
+	git("commit", "-m", "add impl")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken", ".git"), []byte("gitdir: ../missing\n"), 0644))
+
+	res, err := NewScanner(&ScannerConfig{}).Scan(t.Context(), []string{dir})
+	require.NoError(t, err)
+
+	var relPaths []string
+	for _, f := range res.Files {
+		relPaths = append(relPaths, f.RelativePath)
+	}
+	require.Contains(t, relPaths, "broken/impl.go")
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Default severities of ProcessingError codes, errors without a code are SeverityError
+var defaultSeverities = map[string]Severity{
+	// Syntax errors
+	"pkgident":       SeverityError,
+	"reqident":       SeverityError,
+	"covstatus":      SeverityError,
+	"urlsyntax":      SeverityError,
+	"multisites":     SeverityError,
+	"unmatchedfence": SeverityError,
+	"reqtype":        SeverityError,
+	"statusemoji":    SeverityError,
+	"mergeconflict":  SeverityError,
+	"ignorecode":     SeverityError,
+	// Semantic errors
+	"dupreqid":    SeverityError,
+	"nopkgidreqs": SeverityError,
+	"pkgmismatch": SeverityError,
+}
+
+// ParseSeverity parses the Severity: error, warning or info
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("unknown severity: %s, expected %s, %s or %s", s, SeverityError, SeverityWarning, SeverityInfo)
+}
+
+// ProcessingErrorCodes returns codes of all ProcessingErrors in lexical order
+func ProcessingErrorCodes() []string {
+	codes := make([]string, 0, len(defaultSeverities))
+	for code := range defaultSeverities {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Severities overrides default severities of ProcessingError codes, ref. Config.Severities
+type Severities map[string]Severity
+
+// Of returns the Severity of the code
+func (s Severities) Of(code string) Severity {
+	if severity, ok := s[code]; ok {
+		return severity
+	}
+	if severity, ok := defaultSeverities[code]; ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// apply sets Severity of errs
+func (s Severities) apply(errs []ProcessingError) {
+	for i := range errs {
+		errs[i].Severity = s.Of(errs[i].Code)
+	}
+}
+
+func (s Severities) validate() error {
+	for code, severity := range s {
+		if _, ok := defaultSeverities[code]; !ok {
+			return fmt.Errorf("unknown error code in severities: %s", code)
+		}
+		if _, err := ParseSeverity(string(severity)); err != nil {
+			return fmt.Errorf("severity of %s: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// splitProcessingErrors splits errs into errors that abort processing and the rest
+func splitProcessingErrors(errs []ProcessingError) (blocking, nonBlocking []ProcessingError) {
+	for _, e := range errs {
+		if e.Severity == "" || e.Severity == SeverityError {
+			blocking = append(blocking, e)
+		} else {
+			nonBlocking = append(nonBlocking, e)
+		}
+	}
+	return blocking, nonBlocking
+}
+
+// printNonBlockingErrors prints warnings, infos are printed in verbose mode only
+func printNonBlockingErrors(w io.Writer, errs []ProcessingError) {
+	for _, e := range errs {
+		if e.Severity == SeverityInfo && !IsVerbose {
+			continue
+		}
+		fmt.Fprintf(w, "%s:%d: %s: %s: %s%s\n", e.FilePath, e.Line, e.Severity, e.Code, e.Message, explainHint(e.Code))
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverities(t *testing.T) {
+	severities := Severities{"multisites": SeverityWarning, "reqident": SeverityInfo}
+	require.Equal(t, SeverityWarning, severities.Of("multisites"))
+	require.Equal(t, SeverityError, severities.Of("pkgident"))
+	require.Equal(t, SeverityError, severities.Of(""))
+	require.Equal(t, SeverityError, Severities(nil).Of("multisites"))
+
+	errs := []ProcessingError{
+		{Code: "multisites", FilePath: "req.md", Line: 1, Message: "m"},
+		{Code: "pkgident", FilePath: "req.md", Line: 2, Message: "p"},
+		{Code: "reqident", FilePath: "req.md", Line: 3, Message: "r"},
+	}
+	severities.apply(errs)
+	blocking, nonBlocking := splitProcessingErrors(errs)
+	require.Len(t, blocking, 1)
+	require.Equal(t, "pkgident", blocking[0].Code)
+	require.Len(t, nonBlocking, 2)
+
+	var out bytes.Buffer
+	printNonBlockingErrors(&out, nonBlocking)
+	require.Equal(t, "req.md:1: warning: multisites: m (run reqmd explain multisites)\n", out.String())
+
+	require.Contains(t, ProcessingErrorCodes(), "statusemoji")
+	for _, code := range ProcessingErrorCodes() {
+		require.Equal(t, SeverityError, Severities(nil).Of(code))
+	}
+}
+
+func TestTracer_Severities(t *testing.T) {
+	dir := t.TempDir()
+	path := writeMd(t, dir, "req.md", "- `~REQ001~` `~REQ002~`\n- `~REQ003~`\n")
+
+	trace := func(severities Severities) error {
+		return traceDir(t, &ScannerConfig{NoVCS: true, Severities: severities}, &AnalyzerConfig{Severities: severities}, dir)
+	}
+
+	// Errors abort processing
+	var perr *ProcessingErrors
+	require.ErrorAs(t, trace(nil), &perr)
+	require.Equal(t, "multisites", perr.Errors[0].Code)
+	require.Equal(t, SeverityError, perr.Errors[0].Severity)
+
+	// Warnings do not
+	require.NoError(t, trace(Severities{"multisites": SeverityWarning}))
+	updated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(updated), "- `~REQ003~`uncvrd[^1]❓\n")
+}
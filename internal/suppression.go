@@ -0,0 +1,75 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Suppression is a `reqmd:ignore <codes>` comment of a markdown file.
+// ProcessingErrors with the codes are suppressed at the line of the comment and at the next line.
+// Source files produce no ProcessingErrors, so they have no suppressions
+type Suppression struct {
+	Line  int
+	Codes []string
+}
+
+// "<!-- reqmd:ignore reqident, multisites -->"
+var suppressionRegex = regexp.MustCompile(`<!--\s*reqmd:ignore\s+([a-z][a-z0-9]*(?:[\s,]+[a-z][a-z0-9]*)*)[\s,]*-->`)
+
+// parseSuppression returns nil if the line has no suppression comment.
+// Codes are not validated, ref. unknownCodes()
+func parseSuppression(line string, lineNum int) *Suppression {
+	matches := suppressionRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil
+	}
+	return &Suppression{
+		Line: lineNum,
+		Codes: strings.FieldsFunc(matches[1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}),
+	}
+}
+
+// unknownCodes returns codes that are not ProcessingError codes, e.g. typos
+func (s *Suppression) unknownCodes() []string {
+	var res []string
+	for _, code := range s.Codes {
+		if _, ok := defaultSeverities[code]; !ok {
+			res = append(res, code)
+		}
+	}
+	return res
+}
+
+func (s *Suppression) suppresses(e *ProcessingError) bool {
+	return (e.Line == s.Line || e.Line == s.Line+1) && slices.Contains(s.Codes, e.Code)
+}
+
+// suppressErrors splits errs into reported and suppressed ones, suppressions are given per file path
+func suppressErrors(suppressions map[string][]Suppression, errs []ProcessingError) (reported, suppressed []ProcessingError) {
+	for _, e := range errs {
+		if slices.ContainsFunc(suppressions[e.FilePath], func(s Suppression) bool { return s.suppresses(&e) }) {
+			suppressed = append(suppressed, e)
+			continue
+		}
+		reported = append(reported, e)
+	}
+	return reported, suppressed
+}
+
+// verboseSuppressedErrors reports the number of suppressed errors and the errors in verbose mode
+func verboseSuppressedErrors(phase string, errs []ProcessingError) {
+	if len(errs) == 0 {
+		return
+	}
+	Verbose("Suppressed "+phase+" errors", "count", len(errs))
+	for _, e := range errs {
+		Verbose("  " + e.FilePath + ":" + strconv.Itoa(e.Line) + ": " + e.Code + ": " + e.Message)
+	}
+}
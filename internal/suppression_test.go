@@ -0,0 +1,78 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSuppression(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string // nil if the line is not a suppression
+	}{
+		{"<!-- reqmd:ignore reqident -->", []string{"reqident"}},
+		{"<!--reqmd:ignore reqident, multisites-->", []string{"reqident", "multisites"}},
+		{"- `~a-b~` <!-- reqmd:ignore reqident -->", []string{"reqident"}},
+		{"`reqmd:ignore reqident`", nil},
+		{"<!-- reqmd:ignore -->", nil},
+		{"// reqmd:ignore reqident", nil},
+	}
+	for _, tt := range tests {
+		s := parseSuppression(tt.line, 5)
+		if tt.want == nil {
+			require.Nil(t, s, tt.line)
+			continue
+		}
+		require.NotNil(t, s, tt.line)
+		require.Equal(t, Suppression{Line: 5, Codes: tt.want}, *s, tt.line)
+	}
+}
+
+func TestParseFile_Suppressions(t *testing.T) {
+	content := mdHeader +
+		"<!-- reqmd:ignore reqident -->\n" +
+		"- `~a-b~`\n" +
+		"- `~c-d~`\n" +
+		"- `~e~` `~f~` <!-- reqmd:ignore reqident -->\n" +
+		"```\n" +
+		"<!-- reqmd:ignore reqident -->\n" +
+		"```\n"
+
+	structure, errs, err := parseFileEx(&ScannerContext{}, "req.md", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, structure.Suppressions, 2)
+
+	// Only the line of the comment and the next line are suppressed, only given codes are suppressed
+	require.Len(t, errs, 2)
+	require.Equal(t, "reqident", errs[0].Code)
+	require.Equal(t, 7, errs[0].Line)
+	require.Equal(t, "multisites", errs[1].Code)
+	require.Len(t, structure.SuppressedErrors, 1)
+	require.Equal(t, 6, structure.SuppressedErrors[0].Line)
+}
+
+// Unknown codes are reported, source files have no suppressions
+func TestParseFile_SuppressionCodes(t *testing.T) {
+	content := mdHeader +
+		"<!-- reqmd:ignore reqidnet, multisites -->\n" +
+		"- `~a-b~`\n"
+
+	structure, errs, err := parseFileEx(&ScannerContext{}, "req.md", strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, structure.Suppressions, 1)
+	require.Len(t, errs, 2)
+	require.Equal(t, "ignorecode", errs[0].Code)
+	require.Equal(t, 5, errs[0].Line)
+	require.Contains(t, errs[0].Message, "reqidnet")
+	require.Equal(t, "reqident", errs[1].Code)
+
+	structure, errs, err = parseFileEx(&ScannerContext{}, "impl.go", strings.NewReader("// reqmd:ignore reqidnet\n"))
+	require.NoError(t, err)
+	require.Empty(t, structure.Suppressions)
+	require.Empty(t, errs)
+}
@@ -42,6 +42,26 @@ func Test_systest_reqsrc(t *testing.T) {
 	runSysTest(t, "reqsrc")
 }
 
+// Summary blocks are filled in
+func Test_systest_summary(t *testing.T) {
+	runSysTest(t, "summary")
+}
+
+// Footnotes that are not referenced by RequirementSites are removed
+func Test_systest_orphans(t *testing.T) {
+	runSysTest(t, "orphans")
+}
+
+// Mismatched and missing CoverageStatusEmojis are repaired
+func Test_systest_statusemoji(t *testing.T) {
+	runSysTest(t, "statusemoji")
+}
+
+// Footnotes of RequirementSites on ignored lines are kept
+func Test_systest_ignorelines(t *testing.T) {
+	runSysTestEx(t, "ignorelines", []string{"--ignore-lines", "^// line:"})
+}
+
 func runSysTest(t *testing.T, testID string) {
 	systrun.RunSysTest(t, sysTestsDir, testID, ExecRootCmd, Version)
 }
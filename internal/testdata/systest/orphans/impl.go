@@ -0,0 +1,4 @@
+package orphans
+
+// [~orphans/cmp.Handler~impl]
+func Handler() {}
@@ -0,0 +1,4 @@
+package statusemoji
+
+// [~statusemoji/cmp.Covered~impl]
+func Covered() {}
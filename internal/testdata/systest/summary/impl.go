@@ -0,0 +1,4 @@
+package summary
+
+// [~summary/cmp.Handler~impl]
+func Handler() {}
@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,11 +18,13 @@ An exerpt from design.md
 
 - **Purpose**: Implements `ITracer`. This is the **facade** that orchestrates the scanning, analyzing, and applying phases.
 - **Key functions**:
-  - `Trace()
+  - `Trace(ctx)`, scanning and applying stop when ctx is done
 - **Responsibilities**:
   - High-level workflow control.
   - Enforce the steps: if syntax errors exist, abort; if semantic errors exist, abort; otherwise apply actions.
+  - Errors with SeverityWarning and SeverityInfo do not abort, they are printed.
   - Use injected interfaced (ref. interfaces.go) IScanner, IAnalyzer, IApplier to scan, analyze, and apply changes.
+  - Use injected IVerifier (if any) to check that applied changes are idempotent.
 
 */
 
@@ -29,25 +32,28 @@ type tracer struct {
 	scanner  IScanner
 	analyzer IAnalyzer
 	applier  IApplier
-	paths    []string // For multi-path approach
+	verifier IVerifier // nil if applied changes are not verified
+	paths    []string  // For multi-path approach
 }
 
-// NewTracer creates a tracer that handles multiple paths for both markdown and source files
-func NewTracer(scanner IScanner, analyzer IAnalyzer, applier IApplier, paths []string) ITracer {
+// NewTracer creates a tracer that handles multiple paths for both markdown and source files.
+// verifier can be nil, e.g. for the dry-run mode
+func NewTracer(scanner IScanner, analyzer IAnalyzer, applier IApplier, verifier IVerifier, paths []string) ITracer {
 	return &tracer{
 		scanner:  scanner,
 		analyzer: analyzer,
 		applier:  applier,
+		verifier: verifier,
 		paths:    paths,
 	}
 }
 
-func (t *tracer) Trace() error {
-	return t.trace()
+func (t *tracer) Trace(ctx context.Context) error {
+	return t.trace(ctx)
 }
 
 // traceMultiPath handles the new unified approach where multiple paths can contain both markdown and source files
-func (t *tracer) trace() error {
+func (t *tracer) trace(ctx context.Context) error {
 	// Get current dir
 	wd, err := os.Getwd()
 	if err != nil {
@@ -68,27 +74,42 @@ func (t *tracer) trace() error {
 	}
 
 	// Pass all paths to scanner
-	scanResult, err := t.scanner.Scan(absolutePaths)
+	scanResult, err := t.scanner.Scan(ctx, absolutePaths)
 	if err != nil {
 		return err
 	}
-	if len(scanResult.ProcessingErrors) > 0 {
-		return &ProcessingErrors{Errors: scanResult.ProcessingErrors}
+	verboseSuppressedErrors("syntax", scanResult.SuppressedErrors)
+	errs, warnings := splitProcessingErrors(scanResult.ProcessingErrors)
+	if len(errs) > 0 {
+		return &ProcessingErrors{Errors: errs}
 	}
+	printNonBlockingErrors(os.Stderr, warnings)
 
 	// Analyzing phase (same as before)
 	analyzeResult, err := t.analyzer.Analyze(scanResult.Files)
 	if err != nil {
 		return err
 	}
-	if len(analyzeResult.ProcessingErrors) > 0 {
-		return &ProcessingErrors{Errors: analyzeResult.ProcessingErrors}
+	verboseSuppressedErrors("semantic", analyzeResult.SuppressedErrors)
+	errs, warnings = splitProcessingErrors(analyzeResult.ProcessingErrors)
+	if len(errs) > 0 {
+		return &ProcessingErrors{Errors: errs}
 	}
+	printNonBlockingErrors(os.Stderr, warnings)
 
 	// Applying phase (same as before)
-	if err := t.applier.Apply(analyzeResult); err != nil {
+	// Analysis is not interruptible, so ctx is checked before applying
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	if err := t.applier.Apply(ctx, analyzeResult); err != nil {
+		return err
+	}
+
+	// Verification phase
+	if t.verifier != nil {
+		return t.verifier.Verify(scanResult.Files, analyzeResult)
+	}
 
 	return nil
 }
@@ -0,0 +1,30 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mdHeader is the header of test markdown files, the PackageId is `pkg`
+const mdHeader = "---\nreqmd.package: pkg\n---\n\n"
+
+// writeMd writes mdHeader followed by body to the markdown file name in dir and returns the path of the file
+func writeMd(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(mdHeader+body), 0644))
+	return path
+}
+
+// traceDir traces dir using scfg and analyzers that are configured by acfg, the changes are verified
+func traceDir(t *testing.T, scfg *ScannerConfig, acfg *AnalyzerConfig, dir string) error {
+	t.Helper()
+	newAnalyzer := func() IAnalyzer { return NewAnalyzerEx(acfg) }
+	return NewTracer(NewScanner(scfg), newAnalyzer(), NewApplier(&ApplierConfig{}), NewVerifier(scfg, newAnalyzer), []string{dir}).Trace(t.Context())
+}
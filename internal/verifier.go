@@ -0,0 +1,122 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type verifier struct {
+	sctx        *ScannerContext
+	severities  Severities
+	baseline    *Baseline
+	newAnalyzer func() IAnalyzer
+	// Changed files are re-parsed from the git index, ref. GitConfig.StagedContent
+	stagedContent bool
+}
+
+// NewVerifier creates a verifier that re-parses changed markdown files using scfg
+// and analyzes them using a fresh analyzer created by newAnalyzer.
+// Files are re-parsed from the git index if scfg.GitConfig.StagedContent is set, ref. NewStagingApplier()
+func NewVerifier(scfg *ScannerConfig, newAnalyzer func() IAnalyzer) IVerifier {
+	return &verifier{
+		sctx: &ScannerContext{
+			TypeRegistry:      scfg.TypeRegistry,
+			IgnorePatterns:    scfg.IgnorePatterns,
+			RepairStatusEmoji: scfg.RepairStatusEmoji,
+		},
+		severities:    scfg.Severities,
+		baseline:      scfg.Baseline,
+		newAnalyzer:   newAnalyzer,
+		stagedContent: scfg.GitConfig != nil && scfg.GitConfig.StagedContent,
+	}
+}
+
+func (v *verifier) parseFile(path string) (*FileStructure, []ProcessingError, error) {
+	if !v.stagedContent {
+		return parseFile(v.sctx, path)
+	}
+	content, err := readStagedFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseFileEx(v.sctx, path, bytes.NewReader(content))
+}
+
+// Verify re-parses the files changed by the applied actions, replaces them in files and analyzes the result in memory.
+// Remaining actions or new errors mean that a repeated trace would change the files again.
+func (v *verifier) Verify(files []FileStructure, applied *AnalyzerResult) error {
+	if len(applied.MdActions) == 0 {
+		return nil
+	}
+
+	var errs []ProcessingError
+	reparsed := make([]FileStructure, 0, len(files))
+	for _, file := range files {
+		if _, changed := applied.MdActions[file.Path]; !changed {
+			reparsed = append(reparsed, file)
+			continue
+		}
+		structure, syntaxErrs, err := v.parseFile(file.Path)
+		if err != nil {
+			return err
+		}
+		syntaxErrs, _ = v.baseline.split(syntaxErrs)
+		v.severities.apply(syntaxErrs)
+		errs = append(errs, syntaxErrs...)
+		structure.FileHash = file.FileHash
+		structure.RelativePath = file.RelativePath
+		structure.RepoRootFolderURL = file.RepoRootFolderURL
+		structure.CoverageURLTemplate = file.CoverageURLTemplate
+		reparsed = append(reparsed, *structure)
+	}
+
+	res, err := v.newAnalyzer().Analyze(reparsed)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, res.ProcessingErrors...)
+	// Warnings are already reported by the tracer
+	errs, _ = splitProcessingErrors(errs)
+
+	if len(errs) == 0 && len(res.MdActions) == 0 {
+		Verbose("Verification passed", "files", len(applied.MdActions))
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("reqmd: verification failed, repeated trace would not be idempotent")
+	if len(errs) > 0 {
+		sb.WriteString("\nerrors in changed files:\n")
+		sb.WriteString((&ProcessingErrors{Errors: errs}).Error())
+	}
+	if len(res.MdActions) > 0 {
+		sb.WriteString("\nactions that are still required:")
+		for _, path := range sortedMdActionPaths(res.MdActions) {
+			lines := verifierFileLines(path)
+			for _, action := range res.MdActions[path] {
+				fmt.Fprintf(&sb, "\n%s:%d: %s: %s", path, action.Line, action.Type, action.RequirementName)
+				if action.Line > 0 && action.Line <= len(lines) {
+					fmt.Fprintf(&sb, "\n\tcurrent:  %s", lines[action.Line-1])
+				}
+				fmt.Fprintf(&sb, "\n\texpected: %s", action.Data)
+			}
+		}
+	}
+	return errors.New(sb.String())
+}
+
+// verifierFileLines returns lines of the file, or nil if the file can't be read
+func verifierFileLines(path FilePath) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines, _ := splitLinesPreserveEndings(content)
+	return lines
+}
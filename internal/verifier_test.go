@@ -0,0 +1,47 @@
+// Copyright (c) 2025-present unTill Software Development Group B. V. and Contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "req.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nreqmd.package: pkg1\n---\n\n`~REQ001~`\n`~REQ002~`\n"), 0644))
+
+	src := createSourceFileStructure("src/impl.go", "https://github.com/org/repo/blob/main", []CoverageTag{
+		createCoverageTag(StrToReqId("pkg1/REQ001"), "impl", 20),
+	})
+
+	md, errs, err := parseFile(newMdCtx(), path)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	files := []FileStructure{*md, src}
+
+	ar, err := NewAnalyzer().Analyze(files)
+	require.NoError(t, err)
+	require.NotEmpty(t, ar.MdActions)
+	require.NoError(t, NewApplier(&ApplierConfig{}).Apply(t.Context(), ar))
+
+	verifier := NewVerifier(&ScannerConfig{}, NewAnalyzer)
+	require.NoError(t, verifier.Verify(files, ar))
+
+	// Coverage status is changed by hand, so the repeated analysis still produces actions
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines, hasCRLF := splitLinesPreserveEndings(content)
+	lines[5] = "`~REQ002~`covrd[^2]✅"
+	require.NoError(t, os.WriteFile(path, joinLinesPreserveEndings(lines, hasCRLF), 0644))
+
+	err = verifier.Verify(files, ar)
+	require.ErrorContains(t, err, "verification failed")
+	require.ErrorContains(t, err, path+":6: Site: REQ002")
+	require.ErrorContains(t, err, "current:  `~REQ002~`covrd[^2]✅")
+	require.ErrorContains(t, err, "expected: `~REQ002~`uncvrd[^2]❓")
+}
@@ -4,14 +4,20 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"os"
+	"os/signal"
 
 	"github.com/voedger/reqmd/internal"
 )
 
 func main() {
-	if err := internal.ExecRootCmd(os.Args, internal.Version); err != nil {
+	// Ctrl-C stops processing, changes of files are applied all or none
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := internal.ExecRootCmdEx(ctx, os.Args, internal.Version)
+	stop()
+	if err != nil {
 		os.Exit(1)
 	}
 }